@@ -0,0 +1,253 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"sort"
+)
+
+// VerifyAllocation checks that shares add up exactly to total. It returns
+// false when the sum differs and ErrMismatchingCurrency when a share is in a
+// different currency than total. Nil shares are ignored.
+func VerifyAllocation(total *Money, shares []*Money) (bool, error) {
+	if !IsValid(total) {
+		return false, ErrInvalidValue
+	}
+	for _, s := range shares {
+		if s == nil {
+			continue
+		}
+		if _, err := matchCurrency(total.GetCurrencyCode(), s.GetCurrencyCode()); err != nil {
+			return false, err
+		}
+	}
+	sum, _, err := sumNanos(shares)
+	if err != nil {
+		return false, err
+	}
+	return sum.Cmp(toNanos(total)) == 0, nil
+}
+
+// VerifyInvoice checks that lineItems add up exactly to the stated total of
+// an invoice. When they don't it also returns the discrepancy, the sum of the
+// line items minus total, so a positive discrepancy means the items exceed
+// the total. Nil line items are ignored.
+func VerifyInvoice(total *Money, lineItems []*Money) (bool, *Money, error) {
+	if total == nil || !IsValid(total) {
+		return false, nil, ErrInvalidValue
+	}
+	sum, code, err := sumNanos(lineItems)
+	if err != nil {
+		return false, nil, err
+	}
+	if code, err = matchCurrency(total.CurrencyCode, code); err != nil {
+		return false, nil, err
+	}
+	diff := sum.Sub(sum, toNanos(total))
+	if diff.Sign() == 0 {
+		return true, nil, nil
+	}
+	discrepancy, err := fromNanos(diff, code)
+	if err != nil {
+		return false, nil, err
+	}
+	return false, discrepancy, nil
+}
+
+// AllocateWithMinimum splits total into as many shares of minShare as fit, up
+// to maxShares, and adds whatever is left over to the last share. Every share
+// is therefore at least minShare. It returns ErrTotalBelowMinimum if total is
+// smaller than minShare.
+func AllocateWithMinimum(total *Money, minShare *Money, maxShares int) ([]*Money, error) {
+	if maxShares <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if total == nil || !IsValid(total) || !IsValid(minShare) || !IsPositive(minShare) {
+		return nil, ErrInvalidValue
+	}
+	code, err := matchCurrency(total.GetCurrencyCode(), minShare.GetCurrencyCode())
+	if err != nil {
+		return nil, err
+	}
+
+	totalNanos, minNanos := toNanos(total), toNanos(minShare)
+	if totalNanos.Cmp(minNanos) < 0 {
+		return nil, ErrTotalBelowMinimum
+	}
+	count := new(big.Int).Quo(totalNanos, minNanos)
+	if count.Cmp(big.NewInt(int64(maxShares))) > 0 {
+		count.SetInt64(int64(maxShares))
+	}
+
+	n := int(count.Int64())
+	shares := make([]*Money, n)
+	for i := 0; i < n-1; i++ {
+		shares[i] = &Money{Units: minShare.Units, Nanos: minShare.Nanos, CurrencyCode: code}
+	}
+	last := new(big.Int).Mul(minNanos, big.NewInt(int64(n-1)))
+	if shares[n-1], err = fromNanos(last.Sub(totalNanos, last), code); err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// DistributeProportional splits fee across items in proportion to their
+// amounts. Each share is rounded down to a whole nano and the nanos left over
+// go to the shares with the largest remainders, earliest first, so the shares
+// always add up to fee exactly. Items must not be negative and must not all
+// be zero.
+func DistributeProportional(fee *Money, items []*Money) ([]*Money, error) {
+	if fee == nil || !IsValid(fee) {
+		return nil, ErrInvalidValue
+	}
+	weights := make([]*big.Int, len(items))
+	for i, m := range items {
+		if m == nil || !IsValid(m) {
+			return nil, ErrInvalidValue
+		}
+		if _, err := matchCurrency(fee.CurrencyCode, m.CurrencyCode); err != nil {
+			return nil, err
+		}
+		if weights[i] = toNanos(m); weights[i].Sign() < 0 {
+			return nil, ErrInvalidValue
+		}
+	}
+
+	nanos, err := allocateNanos(toNanos(fee), weights)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]*Money, len(nanos))
+	for i, n := range nanos {
+		if shares[i], err = fromNanos(n, fee.CurrencyCode); err != nil {
+			return nil, err
+		}
+	}
+	return shares, nil
+}
+
+// AllocateWithCaps fills buckets with total in order, giving each as much as
+// its cap allows before moving on to the next, so the shares add up to total
+// exactly and no share exceeds its cap. Buckets left over get zero. It
+// returns ErrCapsExceeded if total is larger than the sum of the caps. total
+// and the caps must not be negative.
+func AllocateWithCaps(total *Money, caps []*Money) ([]*Money, error) {
+	if total == nil || !IsValid(total) || IsNegative(total) {
+		return nil, ErrInvalidValue
+	}
+	code := total.CurrencyCode
+	for _, c := range caps {
+		if c == nil || !IsValid(c) || IsNegative(c) {
+			return nil, ErrInvalidValue
+		}
+		var err error
+		if code, err = matchCurrency(code, c.CurrencyCode); err != nil {
+			return nil, err
+		}
+	}
+
+	rest := toNanos(total)
+	shares := make([]*Money, len(caps))
+	for i, c := range caps {
+		n := toNanos(c)
+		if rest.Cmp(n) < 0 {
+			n.Set(rest)
+		}
+		rest.Sub(rest, n)
+		var err error
+		if shares[i], err = fromNanos(n, code); err != nil {
+			return nil, err
+		}
+	}
+	if rest.Sign() != 0 {
+		return nil, ErrCapsExceeded
+	}
+	return shares, nil
+}
+
+// allocateNanos splits total in proportion to the non-negative weights using
+// the largest remainder method.
+func allocateNanos(total *big.Int, weights []*big.Int) ([]*big.Int, error) {
+	weightSum := new(big.Int)
+	for _, w := range weights {
+		weightSum.Add(weightSum, w)
+	}
+	if weightSum.Sign() == 0 {
+		return nil, ErrZeroTotal
+	}
+
+	shares := make([]*big.Int, len(weights))
+	remainders := make([]*big.Int, len(weights))
+	left := new(big.Int).Set(total)
+	for i, w := range weights {
+		product := new(big.Int).Mul(total, w)
+		// DivMod rounds towards negative infinity for a positive divisor,
+		// so every remainder is non-negative.
+		shares[i], remainders[i] = new(big.Int).DivMod(product, weightSum, new(big.Int))
+		left.Sub(left, shares[i])
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]].Cmp(remainders[order[b]]) > 0
+	})
+	one := big.NewInt(1)
+	for i := 0; left.Sign() > 0; i++ {
+		shares[order[i]].Add(shares[order[i]], one)
+		left.Sub(left, one)
+	}
+	return shares, nil
+}
+
+// MakeChange breaks amount down greedily into the given denominations,
+// taking as many of the largest one as fit before moving on to the next. It
+// returns the count used of each denomination, keyed by its decimal amount
+// such as "0.25", and whatever couldn't be represented. Denominations must be
+// positive and amount must not be negative; nil denominations are skipped.
+// It returns ErrOverflow if a count doesn't fit in an int.
+func MakeChange(amount *Money, denominations []*Money) (map[string]int, *Money, error) {
+	if amount == nil || !IsValid(amount) || IsNegative(amount) {
+		return nil, nil, ErrInvalidValue
+	}
+	code := amount.GetCurrencyCode()
+	values := make([]*big.Int, 0, len(denominations))
+	keys := make(map[*big.Int]string, len(denominations))
+	for _, d := range denominations {
+		if d == nil {
+			continue
+		}
+		if !IsPositive(d) {
+			return nil, nil, ErrInvalidValue
+		}
+		var err error
+		if code, err = matchCurrency(code, d.CurrencyCode); err != nil {
+			return nil, nil, err
+		}
+		n := toNanos(d)
+		values = append(values, n)
+		keys[n] = decimalString(d)
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Cmp(values[j]) > 0 })
+
+	counts := make(map[string]int)
+	rest := toNanos(amount)
+	for _, v := range values {
+		if rest.Cmp(v) < 0 {
+			continue
+		}
+		q, r := new(big.Int).QuoRem(rest, v, new(big.Int))
+		if !q.IsInt64() || q.Int64() > int64(math.MaxInt) {
+			return nil, nil, ErrOverflow
+		}
+		counts[keys[v]] += int(q.Int64())
+		rest = r
+	}
+	remainder, err := fromNanos(rest, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	return counts, remainder, nil
+}
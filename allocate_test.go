@@ -0,0 +1,295 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestVerifyAllocation(t *testing.T) {
+	total := &Money{Units: 10, Nanos: 0, CurrencyCode: "USD"}
+	cases := []struct {
+		name     string
+		shares   []*Money
+		expected bool
+		err      error
+	}{
+		{
+			"exact",
+			[]*Money{
+				{Units: 3, Nanos: 340000000, CurrencyCode: "USD"},
+				{Units: 3, Nanos: 330000000, CurrencyCode: "USD"},
+				{Units: 3, Nanos: 330000000, CurrencyCode: "USD"},
+			},
+			true,
+			nil,
+		},
+		{
+			"off by one nano",
+			[]*Money{
+				{Units: 3, Nanos: 333333333, CurrencyCode: "USD"},
+				{Units: 3, Nanos: 333333333, CurrencyCode: "USD"},
+				{Units: 3, Nanos: 333333333, CurrencyCode: "USD"},
+			},
+			false,
+			nil,
+		},
+		{
+			"mismatching currency",
+			[]*Money{
+				{Units: 5, CurrencyCode: "USD"},
+				{Units: 5, CurrencyCode: "EUR"},
+			},
+			false,
+			ErrMismatchingCurrency,
+		},
+	}
+
+	for _, v := range cases {
+		res, err := VerifyAllocation(total, v.shares)
+		if err != v.err {
+			t.Errorf("%s: got error %v expected %v", v.name, err, v.err)
+		}
+		if res != v.expected {
+			t.Errorf("%s: got %v expected %v", v.name, res, v.expected)
+		}
+	}
+}
+
+func TestAllocateWithMinimum(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	cases := []struct {
+		name      string
+		total     *Money
+		minShare  *Money
+		maxShares int
+		expected  []*Money
+		err       error
+	}{
+		{"limited by minimum", usd(25, 0), usd(10, 0), 5, []*Money{usd(10, 0), usd(15, 0)}, nil},
+		{"limited by max shares", usd(100, 0), usd(10, 0), 3, []*Money{usd(10, 0), usd(10, 0), usd(80, 0)}, nil},
+		{"exact", usd(30, 0), usd(10, 0), 3, []*Money{usd(10, 0), usd(10, 0), usd(10, 0)}, nil},
+		{"nanos remainder", usd(1, 0), usd(0, 300000000), 10, []*Money{usd(0, 300000000), usd(0, 300000000), usd(0, 400000000)}, nil},
+		{"single share", usd(10, 0), usd(10, 0), 1, []*Money{usd(10, 0)}, nil},
+		{"below minimum", usd(9, 990000000), usd(10, 0), 3, nil, ErrTotalBelowMinimum},
+		{"mismatching currency", usd(10, 0), &Money{Units: 1, CurrencyCode: "EUR"}, 3, nil, ErrMismatchingCurrency},
+		{"zero minimum", usd(10, 0), usd(0, 0), 3, nil, ErrInvalidValue},
+		{"no shares", usd(10, 0), usd(1, 0), 0, nil, ErrInvalidQuantity},
+	}
+
+	for _, v := range cases {
+		res, err := AllocateWithMinimum(v.total, v.minShare, v.maxShares)
+		if err != v.err {
+			t.Errorf("%s: got error %v expected %v", v.name, err, v.err)
+			continue
+		}
+		if len(res) != len(v.expected) {
+			t.Errorf("%s: got %v expected %v", v.name, res, v.expected)
+			continue
+		}
+		for i := range res {
+			if !Equals(res[i], v.expected[i]) {
+				t.Errorf("%s: got %v expected %v", v.name, res, v.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestDistributeProportional(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	cases := []struct {
+		name     string
+		fee      *Money
+		items    []*Money
+		expected []*Money
+		err      error
+	}{
+		{
+			"exact",
+			usd(10, 0), []*Money{usd(20, 0), usd(30, 0), usd(50, 0)},
+			[]*Money{usd(2, 0), usd(3, 0), usd(5, 0)}, nil,
+		},
+		{
+			"remainder to largest fraction",
+			usd(0, 10), []*Money{usd(1, 0), usd(1, 0), usd(1, 0)},
+			[]*Money{usd(0, 4), usd(0, 3), usd(0, 3)}, nil,
+		},
+		{
+			"remainder by size of fraction",
+			usd(0, 100), []*Money{usd(1, 0), usd(2, 0), usd(4, 0)},
+			[]*Money{usd(0, 14), usd(0, 29), usd(0, 57)}, nil,
+		},
+		{
+			"negative fee",
+			usd(0, -10), []*Money{usd(1, 0), usd(1, 0), usd(1, 0)},
+			[]*Money{usd(0, -3), usd(0, -3), usd(0, -4)}, nil,
+		},
+		{
+			"zero item",
+			usd(5, 0), []*Money{usd(0, 0), usd(7, 0)},
+			[]*Money{usd(0, 0), usd(5, 0)}, nil,
+		},
+		{"zero total", usd(5, 0), []*Money{usd(0, 0), usd(0, 0)}, nil, ErrZeroTotal},
+		{"negative item", usd(5, 0), []*Money{usd(-1, 0), usd(7, 0)}, nil, ErrInvalidValue},
+		{"mismatching currency", usd(5, 0), []*Money{{Units: 1, CurrencyCode: "EUR"}}, nil, ErrMismatchingCurrency},
+	}
+
+	for _, v := range cases {
+		res, err := DistributeProportional(v.fee, v.items)
+		if err != v.err {
+			t.Errorf("%s: got error %v expected %v", v.name, err, v.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if ok, _ := VerifyAllocation(v.fee, res); !ok {
+			t.Errorf("%s: shares %v don't add up to %v", v.name, res, v.fee)
+		}
+		for i := range v.expected {
+			if !Equals(res[i], v.expected[i]) {
+				t.Errorf("%s: got %v expected %v", v.name, res, v.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestMakeChange(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	denominations := []*Money{usd(0, 100000000), usd(1, 0), usd(0, 250000000), usd(5, 0), usd(0, 50000000)}
+	cases := []struct {
+		name      string
+		amount    *Money
+		counts    map[string]int
+		remainder *Money
+	}{
+		{"exact", usd(7, 400000000), map[string]int{"5": 1, "1": 2, "0.25": 1, "0.1": 1, "0.05": 1}, usd(0, 0)},
+		{"remainder", usd(0, 370000000), map[string]int{"0.25": 1, "0.1": 1}, usd(0, 20000000)},
+		{"too small", usd(0, 40000000), map[string]int{}, usd(0, 40000000)},
+		{"zero", usd(0, 0), map[string]int{}, usd(0, 0)},
+	}
+
+	for _, v := range cases {
+		counts, remainder, err := MakeChange(v.amount, denominations)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", v.name, err)
+			continue
+		}
+		if len(counts) != len(v.counts) {
+			t.Errorf("%s: got %v expected %v", v.name, counts, v.counts)
+		}
+		for k, c := range v.counts {
+			if counts[k] != c {
+				t.Errorf("%s: got %d of %s expected %d", v.name, counts[k], k, c)
+			}
+		}
+		if *remainder != *v.remainder {
+			t.Errorf("%s: got remainder %v expected %v", v.name, remainder, v.remainder)
+		}
+	}
+}
+
+func TestMakeChangeErrors(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	cases := []struct {
+		name          string
+		amount        *Money
+		denominations []*Money
+		err           error
+	}{
+		{"mismatching currency", usd(5, 0), []*Money{{Units: 1, CurrencyCode: "EUR"}}, ErrMismatchingCurrency},
+		{"negative amount", usd(-5, 0), []*Money{usd(1, 0)}, ErrInvalidValue},
+		{"nil amount", nil, []*Money{usd(1, 0)}, ErrInvalidValue},
+		{"zero denomination", usd(5, 0), []*Money{usd(0, 0)}, ErrInvalidValue},
+		{"negative denomination", usd(5, 0), []*Money{usd(-1, 0)}, ErrInvalidValue},
+		{"count overflow", usd(10000000000, 0), []*Money{usd(0, 1)}, ErrOverflow},
+	}
+
+	for _, v := range cases {
+		if _, _, err := MakeChange(v.amount, v.denominations); err != v.err {
+			t.Errorf("%s: got error %v expected %v", v.name, err, v.err)
+		}
+	}
+}
+
+func TestVerifyInvoice(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	items := []*Money{usd(19, 130000000), usd(5, 990000000), nil, usd(0, 880000000)}
+	cases := []struct {
+		name        string
+		total       *Money
+		items       []*Money
+		ok          bool
+		discrepancy *Money
+		err         error
+	}{
+		{"matching", usd(26, 0), items, true, nil, nil},
+		{"items over by a nano", usd(25, 999999999), items, false, usd(0, 1), nil},
+		{"items under by a nano", usd(26, 1), items, false, usd(0, -1), nil},
+		{"no items", usd(0, 0), nil, true, nil, nil},
+		{"mismatching currency", &Money{Units: 26, CurrencyCode: "EUR"}, items, false, nil, ErrMismatchingCurrency},
+		{"invalid item", usd(26, 0), []*Money{usd(1, -1)}, false, nil, ErrInvalidValue},
+		{"nil total", nil, items, false, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		ok, discrepancy, err := VerifyInvoice(v.total, v.items)
+		if err != v.err || ok != v.ok {
+			t.Errorf("%s: got %v, %v expected %v, %v", v.name, ok, err, v.ok, v.err)
+			continue
+		}
+		if (discrepancy == nil) != (v.discrepancy == nil) || (discrepancy != nil && *discrepancy != *v.discrepancy) {
+			t.Errorf("%s: got discrepancy %v expected %v", v.name, discrepancy, v.discrepancy)
+		}
+	}
+}
+
+func TestAllocateWithCaps(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	caps := []*Money{usd(10, 0), usd(5, 500000000), usd(20, 0)}
+	cases := []struct {
+		name     string
+		total    *Money
+		caps     []*Money
+		expected []*Money
+		err      error
+	}{
+		{"under the caps", usd(12, 250000000), caps, []*Money{usd(10, 0), usd(2, 250000000), usd(0, 0)}, nil},
+		{"fills the caps", usd(35, 500000000), caps, []*Money{usd(10, 0), usd(5, 500000000), usd(20, 0)}, nil},
+		{"zero", usd(0, 0), caps, []*Money{usd(0, 0), usd(0, 0), usd(0, 0)}, nil},
+		{"over the caps by a nano", usd(35, 500000001), caps, nil, ErrCapsExceeded},
+		{"no caps", usd(1, 0), nil, nil, ErrCapsExceeded},
+		{"mismatching currency", usd(1, 0), []*Money{{Units: 5, CurrencyCode: "EUR"}}, nil, ErrMismatchingCurrency},
+		{"negative cap", usd(1, 0), []*Money{usd(-5, 0)}, nil, ErrInvalidValue},
+		{"negative total", usd(-1, 0), caps, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := AllocateWithCaps(v.total, v.caps)
+		if err != v.err {
+			t.Errorf("%s: got error %v expected %v", v.name, err, v.err)
+			continue
+		}
+		if len(res) != len(v.expected) {
+			t.Errorf("%s: got %v expected %v", v.name, res, v.expected)
+			continue
+		}
+		for i := range res {
+			if *res[i] != *v.expected[i] {
+				t.Errorf("%s: share %d got %v expected %v", v.name, i, res[i], v.expected[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+var nanosPerUnit = big.NewInt(nanosMod)
+
+// toNanos returns the amount of m expressed as a total number of nanos.
+func toNanos(m *Money) *big.Int {
+	n := big.NewInt(m.GetUnits())
+	n.Mul(n, nanosPerUnit)
+	return n.Add(n, big.NewInt(int64(m.GetNanos())))
+}
+
+// fromNanos converts a total number of nanos back to a normalized Money. It
+// returns ErrOverflow if the units don't fit in an int64.
+func fromNanos(n *big.Int, currencyCode string) (*Money, error) {
+	units, nanos := new(big.Int).QuoRem(n, nanosPerUnit, new(big.Int))
+	if !units.IsInt64() {
+		return nil, ErrOverflow
+	}
+	return &Money{
+		Units:        units.Int64(),
+		Nanos:        int32(nanos.Int64()),
+		CurrencyCode: currencyCode,
+	}, nil
+}
+
+// toRat returns the exact amount of m as a rational number of units.
+func toRat(m *Money) *big.Rat {
+	return new(big.Rat).SetFrac(toNanos(m), nanosPerUnit)
+}
+
+// fromRat rounds r to a whole number of nanos according to mode and converts
+// it to Money.
+func fromRat(r *big.Rat, currencyCode string, mode RoundingMode) (*Money, error) {
+	num := new(big.Int).Mul(r.Num(), nanosPerUnit)
+	return fromNanos(roundQuo(num, r.Denom(), mode), currencyCode)
+}
+
+// ratFromFloat returns f as the decimal it prints as, so that a rate of 0.07
+// is exactly 7/100 rather than its binary approximation. f must be finite.
+func ratFromFloat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+// matchCurrency returns the currency code shared by a and b, or
+// ErrMismatchingCurrency if they differ. An empty code stands for an
+// unspecified currency and matches any code; the result then takes the
+// non-empty one.
+func matchCurrency(a, b string) (string, error) {
+	switch {
+	case a == "":
+		return b, nil
+	case b == "" || a == b:
+		return a, nil
+	}
+	return "", ErrMismatchingCurrency
+}
+
+// sumCheckInterval is how many items SumContext adds up between checks of
+// its context.
+const sumCheckInterval = 1024
+
+// sumNanos validates items and returns their total in nanos together with
+// the currency code they share. Nil items are skipped.
+func sumNanos(items []*Money) (*big.Int, string, error) {
+	return sumNanosContext(context.Background(), items)
+}
+
+// sumNanosContext is sumNanos checking ctx every sumCheckInterval items.
+func sumNanosContext(ctx context.Context, items []*Money) (*big.Int, string, error) {
+	total := new(big.Int)
+	code := ""
+	for i, m := range items {
+		if i%sumCheckInterval == 0 {
+			select {
+			case <-ctx.Done():
+				return nil, "", ctx.Err()
+			default:
+			}
+		}
+		if m == nil {
+			continue
+		}
+		if !IsValid(m) {
+			return nil, "", ErrInvalidValue
+		}
+		var err error
+		if code, err = matchCurrency(code, m.CurrencyCode); err != nil {
+			return nil, "", err
+		}
+		total.Add(total, toNanos(m))
+	}
+	return total, code, nil
+}
+
+// Sum returns the exact total of the non-nil items. Every item must be valid
+// and they must share a currency, an empty code matching any other. The
+// total of no items is zero. It returns ErrOverflow if the total doesn't
+// fit, even when the running total only overflows part way through.
+func Sum(items []*Money) (*Money, error) {
+	return SumContext(context.Background(), items)
+}
+
+// SumContext is like Sum but checks ctx periodically while adding and
+// returns ctx.Err() once ctx is done, so that summing a very large slice can
+// be cancelled.
+func SumContext(ctx context.Context, items []*Money) (*Money, error) {
+	total, code, err := sumNanosContext(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	return fromNanos(total, code)
+}
+
+// SumPositive returns the total of the positive items, skipping zero and
+// negative ones. All non-nil items must be valid and share a currency.
+func SumPositive(items []*Money) (*Money, error) {
+	return sumWhere(items, IsPositive)
+}
+
+// SumNegative returns the total of the negative items, skipping zero and
+// positive ones. All non-nil items must be valid and share a currency.
+func SumNegative(items []*Money) (*Money, error) {
+	return sumWhere(items, IsNegative)
+}
+
+// sumWhere returns the total of the items for which keep returns true, after
+// checking all of them with sumNanos.
+func sumWhere(items []*Money, keep func(*Money) bool) (*Money, error) {
+	_, code, err := sumNanos(items)
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	for _, m := range items {
+		if keep(m) {
+			total.Add(total, toNanos(m))
+		}
+	}
+	return fromNanos(total, code)
+}
+
+// PerUnit returns the unit price of total split over quantity items, rounded
+// to the nearest nano with halves rounded away from zero.
+func PerUnit(total *Money, quantity int64) (*Money, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if !IsValid(total) {
+		return nil, ErrInvalidValue
+	}
+	n := roundQuo(toNanos(total), big.NewInt(quantity), HalfUp)
+	return fromNanos(n, total.GetCurrencyCode())
+}
+
+// DivMod splits m into n equal shares of whole nanos and returns the share
+// together with the nanos left over, so that quotient * n + remainder equals
+// m exactly. The quotient is truncated towards zero, which gives the
+// remainder the sign of m and a magnitude smaller than n nanos.
+func DivMod(m *Money, n int64) (quotient *Money, remainder *Money, err error) {
+	if n <= 0 {
+		return nil, nil, ErrInvalidQuantity
+	}
+	if !IsValid(m) {
+		return nil, nil, ErrInvalidValue
+	}
+	q, r := new(big.Int).QuoRem(toNanos(m), big.NewInt(n), new(big.Int))
+	if quotient, err = fromNanos(q, m.GetCurrencyCode()); err != nil {
+		return nil, nil, err
+	}
+	if remainder, err = fromNanos(r, m.GetCurrencyCode()); err != nil {
+		return nil, nil, err
+	}
+	return quotient, remainder, nil
+}
+
+// Mod returns what is left of a after taking out as many whole b as possible.
+// A non-zero result has the same sign as b, so that a equals
+// FloorDiv(a, b) * b + Mod(a, b).
+func Mod(a, b *Money) (*Money, error) {
+	if !IsValid(a) || !IsValid(b) {
+		return nil, ErrInvalidValue
+	}
+	code, err := matchCurrency(a.GetCurrencyCode(), b.GetCurrencyCode())
+	if err != nil {
+		return nil, err
+	}
+	divisor := toNanos(b)
+	if divisor.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+
+	rem := new(big.Int).Rem(toNanos(a), divisor)
+	if rem.Sign() != 0 && rem.Sign() != divisor.Sign() {
+		rem.Add(rem, divisor)
+	}
+	return fromNanos(rem, code)
+}
+
+// FloorDiv returns how many whole times b fits into a, rounding towards
+// negative infinity.
+func FloorDiv(a, b *Money) (int64, error) {
+	if !IsValid(a) || !IsValid(b) {
+		return 0, ErrInvalidValue
+	}
+	if _, err := matchCurrency(a.GetCurrencyCode(), b.GetCurrencyCode()); err != nil {
+		return 0, err
+	}
+	divisor := toNanos(b)
+	if divisor.Sign() == 0 {
+		return 0, ErrDivisionByZero
+	}
+
+	q, rem := new(big.Int).QuoRem(toNanos(a), divisor, new(big.Int))
+	if rem.Sign() != 0 && rem.Sign() != divisor.Sign() {
+		q.Sub(q, big.NewInt(1))
+	}
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return q.Int64(), nil
+}
+
+// Inc returns x plus one nano, carrying into the units when the nanos wrap.
+// A nil x counts as zero. At the top of the representable range a copy of x
+// is returned unchanged.
+func (x *Money) Inc() *Money {
+	return x.addNanos(1)
+}
+
+// Dec returns x minus one nano, borrowing from the units when the nanos
+// wrap. A nil x counts as zero. At the bottom of the representable range a
+// copy of x is returned unchanged.
+func (x *Money) Dec() *Money {
+	return x.addNanos(-1)
+}
+
+func (x *Money) addNanos(delta int64) *Money {
+	n := toNanos(x)
+	res, err := fromNanos(n.Add(n, big.NewInt(delta)), x.GetCurrencyCode())
+	if err != nil {
+		return &Money{Units: x.Units, Nanos: x.Nanos, CurrencyCode: x.CurrencyCode}
+	}
+	return res
+}
+
+// Negate returns -m, flipping the sign of both units and nanos so that they
+// keep matching. A nil m gives nil, and so does a value with units of
+// math.MinInt64, which has no positive counterpart. Callers that need an
+// error for that case can use Sub(nil, m) instead, which returns ErrOverflow.
+func Negate(m *Money) *Money {
+	if m == nil || m.Units == math.MinInt64 {
+		return nil
+	}
+	return &Money{Units: -m.Units, Nanos: -m.Nanos, CurrencyCode: m.CurrencyCode}
+}
+
+// Abs returns the magnitude of m as a new value: m itself if it isn't
+// negative and Negate(m) if it is. A nil m gives nil, and so does a value
+// too negative for its magnitude to fit, for which AbsDiff(m, nil) returns
+// ErrOverflow.
+func Abs(m *Money) *Money {
+	if m == nil {
+		return nil
+	}
+	if IsNegative(m) {
+		return Negate(m)
+	}
+	return &Money{Units: m.Units, Nanos: m.Nanos, CurrencyCode: m.CurrencyCode}
+}
+
+// CanAdd reports whether a and b can be added together: both must be valid
+// and in matching currencies. A nil value counts as zero in any currency.
+func CanAdd(a, b *Money) bool {
+	if !IsValid(a) || !IsValid(b) {
+		return false
+	}
+	if a == nil || b == nil {
+		return true
+	}
+	_, err := matchCurrency(a.CurrencyCode, b.CurrencyCode)
+	return err == nil
+}
+
+// Remaining returns how much is still needed for current to reach target:
+// target - current, or zero once current has reached or passed target.
+func Remaining(current, target *Money) (*Money, error) {
+	return SubFloorZero(target, current)
+}
+
+// SnapToDenominations returns the allowed value closest to m. When m lies
+// exactly halfway between two allowed values the larger one is returned.
+// Nil entries in allowed are skipped.
+func SnapToDenominations(m *Money, allowed []*Money) (*Money, error) {
+	if !IsValid(m) {
+		return nil, ErrInvalidValue
+	}
+	target := toNanos(m)
+	var best, bestDist *big.Int
+	code := m.GetCurrencyCode()
+	for _, a := range allowed {
+		if a == nil {
+			continue
+		}
+		if !IsValid(a) {
+			return nil, ErrInvalidValue
+		}
+		var err error
+		if code, err = matchCurrency(code, a.CurrencyCode); err != nil {
+			return nil, err
+		}
+		n := toNanos(a)
+		dist := new(big.Int).Sub(n, target)
+		dist.Abs(dist)
+		if best == nil || dist.Cmp(bestDist) < 0 || (dist.Cmp(bestDist) == 0 && n.Cmp(best) > 0) {
+			best, bestDist = n, dist
+		}
+	}
+	if best == nil {
+		return nil, ErrEmptySlice
+	}
+	return fromNanos(best, code)
+}
+
+// Add returns a + b, carrying between nanos and units so the result is
+// normalized. Both values must be valid and a nil value counts as zero. It
+// returns ErrMismatchingCurrency if the currency codes differ and
+// ErrOverflow if the sum doesn't fit.
+func Add(a, b *Money) (*Money, error) {
+	return combine(a, b, (*big.Int).Add)
+}
+
+// Sub returns a - b, borrowing between units and nanos so that both carry
+// the sign of the result, as the google.type.Money invariant requires. It
+// checks its arguments like Add.
+func Sub(a, b *Money) (*Money, error) {
+	return combine(a, b, (*big.Int).Sub)
+}
+
+// WouldOverflowAdd reports whether adding a and b would overflow the units
+// of a Money, so that callers can switch to an arbitrary-precision path
+// beforehand. Currency codes are not considered.
+func WouldOverflowAdd(a, b *Money) bool {
+	_, err := fromNanos(new(big.Int).Add(toNanos(a), toNanos(b)), "")
+	return err == ErrOverflow
+}
+
+// SubFloorZero returns a - b, or zero if b exceeds a, for balances that must
+// never go negative.
+func SubFloorZero(a, b *Money) (*Money, error) {
+	return combine(a, b, func(z, x, y *big.Int) *big.Int {
+		if z.Sub(x, y).Sign() < 0 {
+			z.SetInt64(0)
+		}
+		return z
+	})
+}
+
+// AbsDiff returns |a - b|, the non-negative distance between a and b.
+func AbsDiff(a, b *Money) (*Money, error) {
+	return combine(a, b, func(z, x, y *big.Int) *big.Int {
+		return z.Abs(z.Sub(x, y))
+	})
+}
+
+// combine applies op to the nanos of a and b after checking that both are
+// valid and in matching currencies.
+func combine(a, b *Money, op func(z, x, y *big.Int) *big.Int) (*Money, error) {
+	if !IsValid(a) || !IsValid(b) {
+		return nil, ErrInvalidValue
+	}
+	code, err := matchCurrency(a.GetCurrencyCode(), b.GetCurrencyCode())
+	if err != nil {
+		return nil, err
+	}
+	return fromNanos(op(new(big.Int), toNanos(a), toNanos(b)), code)
+}
+
+// MergeSubtotals combines two maps of subtotals keyed by currency code,
+// adding the amounts found under the same key and carrying the others over.
+// The results are new values labelled with their key; a and b are left
+// unchanged. A nil amount counts as zero.
+func MergeSubtotals(a, b map[string]*Money) (map[string]*Money, error) {
+	totals := make(map[string]*big.Int, len(a)+len(b))
+	for _, subtotals := range []map[string]*Money{a, b} {
+		for code, m := range subtotals {
+			if !IsValid(m) {
+				return nil, fmt.Errorf("%s: %w", code, ErrInvalidValue)
+			}
+			if t, ok := totals[code]; ok {
+				t.Add(t, toNanos(m))
+			} else {
+				totals[code] = toNanos(m)
+			}
+		}
+	}
+
+	res := make(map[string]*Money, len(totals))
+	for code, t := range totals {
+		m, err := fromNanos(t, code)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", code, err)
+		}
+		res[code] = m
+	}
+	return res, nil
+}
+
+// SumBy groups the non-nil items by the key keyFn returns for them and
+// totals each group, e.g. by currency code or by sign. The items in a group
+// must share a currency.
+func SumBy[K comparable](items []*Money, keyFn func(*Money) K) (map[K]*Money, error) {
+	groups := make(map[K][]*Money)
+	for _, m := range items {
+		if m != nil {
+			k := keyFn(m)
+			groups[k] = append(groups[k], m)
+		}
+	}
+
+	res := make(map[K]*Money, len(groups))
+	for k, group := range groups {
+		var err error
+		if res[k], err = Sum(group); err != nil {
+			return nil, fmt.Errorf("group %v: %w", k, err)
+		}
+	}
+	return res, nil
+}
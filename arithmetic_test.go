@@ -0,0 +1,706 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestPerUnit(t *testing.T) {
+	cases := []struct {
+		total    *Money
+		quantity int64
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 10, CurrencyCode: "USD"}, 4, &Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, 3, &Money{Units: 3, Nanos: 333333333, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 20, CurrencyCode: "USD"}, 3, &Money{Units: 6, Nanos: 666666667, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 0, Nanos: 5, CurrencyCode: "USD"}, 2, &Money{Units: 0, Nanos: 3, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 0, Nanos: -5, CurrencyCode: "USD"}, 2, &Money{Units: 0, Nanos: -3, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10}, 0, nil, ErrInvalidQuantity},
+		{&Money{Units: 10}, -1, nil, ErrInvalidQuantity},
+		{&Money{Units: -1, Nanos: 1}, 2, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := PerUnit(v.total, v.quantity)
+		if err != v.err {
+			t.Errorf("%v / %d: got error %v expected %v", v.total, v.quantity, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v / %d: got %v expected %v", v.total, v.quantity, res, v.expected)
+		}
+	}
+}
+
+func TestDivMod(t *testing.T) {
+	cases := []struct {
+		m                   *Money
+		n                   int64
+		quotient, remainder *Money
+		err                 error
+	}{
+		{&Money{Units: 10, CurrencyCode: "USD"}, 4, &Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, 3, &Money{Units: 3, Nanos: 333333333, CurrencyCode: "USD"}, &Money{Nanos: 1, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -10, CurrencyCode: "USD"}, 3, &Money{Units: -3, Nanos: -333333333, CurrencyCode: "USD"}, &Money{Nanos: -1, CurrencyCode: "USD"}, nil},
+		{&Money{Nanos: 5}, 7, &Money{}, &Money{Nanos: 5}, nil},
+		{&Money{Units: math.MaxInt64, Nanos: 999999999}, 1, &Money{Units: math.MaxInt64, Nanos: 999999999}, &Money{}, nil},
+		{&Money{Units: 10}, 0, nil, nil, ErrInvalidQuantity},
+		{&Money{Units: 10}, -2, nil, nil, ErrInvalidQuantity},
+		{&Money{Units: 10, Nanos: -1}, 2, nil, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		q, r, err := DivMod(v.m, v.n)
+		if err != v.err {
+			t.Errorf("%v divmod %d: got error %v expected %v", v.m, v.n, err, v.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if *q != *v.quotient || *r != *v.remainder {
+			t.Errorf("%v divmod %d: got %v, %v expected %v, %v", v.m, v.n, q, r, v.quotient, v.remainder)
+		}
+		back := new(big.Int).Mul(toNanos(q), big.NewInt(v.n))
+		if back.Add(back, toNanos(r)).Cmp(toNanos(v.m)) != 0 {
+			t.Errorf("%v divmod %d: %v * %d + %v doesn't add back up", v.m, v.n, q, v.n, r)
+		}
+	}
+}
+
+func TestMod(t *testing.T) {
+	cases := []struct {
+		a, b     *Money
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, CurrencyCode: "USD"}, &Money{Nanos: 300000000, CurrencyCode: "USD"}, &Money{Nanos: 100000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, Nanos: 7, CurrencyCode: "USD"}, &Money{Units: 1, Nanos: 3, CurrencyCode: "USD"}, &Money{Units: 0, Nanos: 999999980, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 2, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, &Money{Units: 2, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -10, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, &Money{Units: 2, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: -3, CurrencyCode: "USD"}, &Money{Units: -2, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, nil, ErrDivisionByZero},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "EUR"}, nil, ErrMismatchingCurrency},
+		{&Money{Units: 10}, &Money{Units: 3}, &Money{Units: 1}, nil},
+		{&Money{Units: 10}, &Money{Units: 3, CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, Nanos: -1, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := Mod(v.a, v.b)
+		if err != v.err {
+			t.Errorf("%v mod %v: got error %v expected %v", v.a, v.b, err, v.err)
+			continue
+		}
+		if v.expected != nil && !Equals(res, v.expected) {
+			t.Errorf("%v mod %v: got %v expected %v", v.a, v.b, res, v.expected)
+		}
+	}
+}
+
+func TestFloorDiv(t *testing.T) {
+	cases := []struct {
+		a, b     *Money
+		expected int64
+		err      error
+	}{
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, 3, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, 4, nil},
+		{&Money{Units: 2, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, 0, nil},
+		{&Money{Units: -10, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, -4, nil},
+		{&Money{Units: -9, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, -3, nil},
+		{&Money{Units: -10, CurrencyCode: "USD"}, &Money{Units: -3, CurrencyCode: "USD"}, 3, nil},
+		{&Money{Units: math.MaxInt64, CurrencyCode: "USD"}, &Money{Nanos: 1, CurrencyCode: "USD"}, 0, ErrOverflow},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, 0, ErrDivisionByZero},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "EUR"}, 0, ErrMismatchingCurrency},
+	}
+
+	for _, v := range cases {
+		res, err := FloorDiv(v.a, v.b)
+		if err != v.err || res != v.expected {
+			t.Errorf("%v / %v: got %d, %v expected %d, %v", v.a, v.b, res, err, v.expected, v.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		// a == FloorDiv(a, b) * b + Mod(a, b)
+		mod, _ := Mod(v.a, v.b)
+		rebuilt := new(big.Int).Mul(big.NewInt(res), toNanos(v.b))
+		if rebuilt.Add(rebuilt, toNanos(mod)).Cmp(toNanos(v.a)) != 0 {
+			t.Errorf("%v / %v: FloorDiv and Mod are inconsistent", v.a, v.b)
+		}
+	}
+}
+
+func TestIncDec(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		inc, dec *Money
+	}{
+		{
+			&Money{Units: 0, Nanos: 999999999, CurrencyCode: "USD"},
+			&Money{Units: 1, Nanos: 0, CurrencyCode: "USD"},
+			&Money{Units: 0, Nanos: 999999998, CurrencyCode: "USD"},
+		},
+		{
+			&Money{Units: 1, Nanos: 0, CurrencyCode: "USD"},
+			&Money{Units: 1, Nanos: 1, CurrencyCode: "USD"},
+			&Money{Units: 0, Nanos: 999999999, CurrencyCode: "USD"},
+		},
+		{
+			&Money{Units: 0, Nanos: 0, CurrencyCode: "USD"},
+			&Money{Units: 0, Nanos: 1, CurrencyCode: "USD"},
+			&Money{Units: 0, Nanos: -1, CurrencyCode: "USD"},
+		},
+		{
+			&Money{Units: -1, Nanos: 0, CurrencyCode: "USD"},
+			&Money{Units: 0, Nanos: -999999999, CurrencyCode: "USD"},
+			&Money{Units: -1, Nanos: -1, CurrencyCode: "USD"},
+		},
+		{
+			&Money{Units: 0, Nanos: -999999999, CurrencyCode: "USD"},
+			&Money{Units: 0, Nanos: -999999998, CurrencyCode: "USD"},
+			&Money{Units: -1, Nanos: 0, CurrencyCode: "USD"},
+		},
+		{
+			&Money{Units: math.MaxInt64, Nanos: 999999999},
+			&Money{Units: math.MaxInt64, Nanos: 999999999},
+			&Money{Units: math.MaxInt64, Nanos: 999999998},
+		},
+		{
+			nil,
+			&Money{Units: 0, Nanos: 1},
+			&Money{Units: 0, Nanos: -1},
+		},
+	}
+
+	for _, v := range cases {
+		if res := v.input.Inc(); *res != *v.inc {
+			t.Errorf("%v.Inc() got %+v expected %+v", v.input, *res, *v.inc)
+		}
+		if res := v.input.Dec(); *res != *v.dec {
+			t.Errorf("%v.Dec() got %+v expected %+v", v.input, *res, *v.dec)
+		}
+	}
+}
+
+func TestNegate(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		expected *Money
+	}{
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, &Money{Units: -19, Nanos: -130000000, CurrencyCode: "USD"}},
+		{&Money{Units: -1, Nanos: -500000000}, &Money{Units: 1, Nanos: 500000000}},
+		{&Money{Nanos: -1, CurrencyCode: "EUR"}, &Money{Nanos: 1, CurrencyCode: "EUR"}},
+		{&Money{CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}},
+		{MaxMoney("USD"), &Money{Units: -math.MaxInt64, Nanos: -999999999, CurrencyCode: "USD"}},
+		{MinMoney("USD"), nil},
+		{&Money{Units: math.MinInt64, CurrencyCode: "USD"}, nil},
+		{nil, nil},
+	}
+
+	for _, v := range cases {
+		res := Negate(v.input)
+		if v.input != nil {
+			// Negate gives up exactly where Sub reports an overflow.
+			diff, err := Sub(nil, v.input)
+			if (res == nil) != (err == ErrOverflow) || res != nil && *res != *diff {
+				t.Errorf("-%v got %v but Sub got %v, %v", v.input, res, diff, err)
+			}
+		}
+		if res == nil || v.expected == nil {
+			if res != v.expected {
+				t.Errorf("-%v got %v expected %v", v.input, res, v.expected)
+			}
+			continue
+		}
+		if *res != *v.expected || !IsValid(res) {
+			t.Errorf("-%v got %v expected %v", v.input, res, v.expected)
+		}
+		if res == v.input {
+			t.Errorf("-%v returned its argument", v.input)
+		}
+	}
+}
+
+func TestAbs(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		expected *Money
+	}{
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}},
+		{&Money{Units: -19, Nanos: -130000000, CurrencyCode: "USD"}, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}},
+		{&Money{Nanos: -1}, &Money{Nanos: 1}},
+		{&Money{Units: -3}, &Money{Units: 3}},
+		{&Money{CurrencyCode: "EUR"}, &Money{CurrencyCode: "EUR"}},
+		{MaxMoney("USD"), MaxMoney("USD")},
+		{MinMoney("USD"), nil},
+		{nil, nil},
+	}
+
+	for _, v := range cases {
+		res := Abs(v.input)
+		if v.input != nil {
+			// Abs gives up exactly where AbsDiff reports an overflow.
+			diff, err := AbsDiff(v.input, nil)
+			if (res == nil) != (err == ErrOverflow) || res != nil && *res != *diff {
+				t.Errorf("|%v| got %v but AbsDiff got %v, %v", v.input, res, diff, err)
+			}
+		}
+		if res == nil || v.expected == nil {
+			if res != v.expected {
+				t.Errorf("|%v| got %v expected %v", v.input, res, v.expected)
+			}
+			continue
+		}
+		if *res != *v.expected {
+			t.Errorf("|%v| got %v expected %v", v.input, res, v.expected)
+		}
+		if res == v.input {
+			t.Errorf("|%v| returned its argument", v.input)
+		}
+	}
+}
+
+func TestCanAdd(t *testing.T) {
+	usd := &Money{Units: 1, CurrencyCode: "USD"}
+	cases := []struct {
+		a, b     *Money
+		expected bool
+	}{
+		{usd, &Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, true},
+		{usd, &Money{Units: 2, CurrencyCode: "EUR"}, false},
+		{usd, &Money{Units: 2, Nanos: -1, CurrencyCode: "USD"}, false},
+		{&Money{Nanos: 1000000000, CurrencyCode: "USD"}, usd, false},
+		{usd, &Money{Units: 2}, true},
+		{usd, nil, true},
+		{nil, nil, true},
+	}
+
+	for _, v := range cases {
+		if res := CanAdd(v.a, v.b); res != v.expected {
+			t.Errorf("CanAdd(%v, %v) got:%v expected:%v", v.a, v.b, res, v.expected)
+		}
+	}
+}
+
+func TestMatchCurrency(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected string
+		err      error
+	}{
+		{"", "", "", nil},
+		{"USD", "USD", "USD", nil},
+		{"", "USD", "USD", nil},
+		{"USD", "", "USD", nil},
+		{"USD", "EUR", "", ErrMismatchingCurrency},
+	}
+
+	for _, v := range cases {
+		res, err := matchCurrency(v.a, v.b)
+		if err != v.err || res != v.expected {
+			t.Errorf("%q, %q: got %q, %v expected %q, %v", v.a, v.b, res, err, v.expected, v.err)
+		}
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	target := &Money{Units: 100, CurrencyCode: "USD"}
+	cases := []struct {
+		current  *Money
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 40, Nanos: 250000000, CurrencyCode: "USD"}, &Money{Units: 59, Nanos: 750000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -5, CurrencyCode: "USD"}, &Money{Units: 105, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 100, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, nil},
+		{&Money{Units: 120, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, nil},
+		{nil, &Money{Units: 100, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 40, CurrencyCode: "EUR"}, nil, ErrMismatchingCurrency},
+		{&Money{Units: 40, Nanos: -1, CurrencyCode: "USD"}, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := Remaining(v.current, target)
+		if err != v.err {
+			t.Errorf("Remaining(%v): got error %v expected %v", v.current, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("Remaining(%v): got %v expected %v", v.current, res, v.expected)
+		}
+	}
+}
+
+func TestSnapToDenominations(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	allowed := []*Money{usd(1, 0), usd(2, 0), usd(5, 0)}
+	cases := []struct {
+		input    *Money
+		allowed  []*Money
+		expected *Money
+		err      error
+	}{
+		{usd(3, 400000000), allowed, usd(2, 0), nil},
+		{usd(3, 600000000), allowed, usd(5, 0), nil},
+		{usd(3, 500000000), allowed, usd(5, 0), nil},
+		{usd(1, 500000000), allowed, usd(2, 0), nil},
+		{usd(-3, 0), allowed, usd(1, 0), nil},
+		{usd(100, 0), []*Money{nil, usd(5, 0), usd(2, 0)}, usd(5, 0), nil},
+		{usd(3, 0), nil, nil, ErrEmptySlice},
+		{usd(3, 0), []*Money{nil}, nil, ErrEmptySlice},
+		{usd(3, 0), []*Money{usd(1, 0), {Units: 2, CurrencyCode: "EUR"}}, nil, ErrMismatchingCurrency},
+		{usd(3, -1), allowed, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := SnapToDenominations(v.input, v.allowed)
+		if err != v.err {
+			t.Errorf("%v: got error %v expected %v", v.input, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v: got %v expected %v", v.input, res, v.expected)
+		}
+	}
+}
+
+func TestMergeSubtotals(t *testing.T) {
+	a := map[string]*Money{
+		"USD": {Units: 10, Nanos: 500000000, CurrencyCode: "USD"},
+		"EUR": {Units: 3, CurrencyCode: "EUR"},
+	}
+	b := map[string]*Money{
+		"USD": {Units: 4, Nanos: 600000000, CurrencyCode: "USD"},
+		"JPY": {Units: 1500, CurrencyCode: "JPY"},
+	}
+	expected := map[string]Money{
+		"USD": {Units: 15, Nanos: 100000000, CurrencyCode: "USD"},
+		"EUR": {Units: 3, CurrencyCode: "EUR"},
+		"JPY": {Units: 1500, CurrencyCode: "JPY"},
+	}
+
+	res, err := MergeSubtotals(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(expected) {
+		t.Errorf("got %v expected %v", res, expected)
+	}
+	for code, m := range expected {
+		if res[code] == nil || *res[code] != m {
+			t.Errorf("%s: got %v expected %v", code, res[code], m)
+		}
+	}
+	if *a["USD"] != (Money{Units: 10, Nanos: 500000000, CurrencyCode: "USD"}) {
+		t.Errorf("input was modified: %v", a["USD"])
+	}
+
+	b["GBP"] = &Money{Units: 1, Nanos: -1, CurrencyCode: "GBP"}
+	if _, err := MergeSubtotals(a, b); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("got error %v expected %v", err, ErrInvalidValue)
+	}
+}
+
+// countdownContext cancels itself once Done has been called n times.
+type countdownContext struct {
+	context.Context
+	cancel context.CancelFunc
+	n      int
+}
+
+func (c *countdownContext) Done() <-chan struct{} {
+	if c.n--; c.n <= 0 {
+		c.cancel()
+	}
+	return c.Context.Done()
+}
+
+func TestSum(t *testing.T) {
+	cases := []struct {
+		items    []*Money
+		expected *Money
+		err      error
+	}{
+		{
+			[]*Money{{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, {Nanos: 900000000, CurrencyCode: "USD"}, {Nanos: 970000000, CurrencyCode: "USD"}},
+			&Money{Units: 21, CurrencyCode: "USD"}, nil,
+		},
+		{
+			[]*Money{{Units: 5, CurrencyCode: "USD"}, nil, {Units: -7, Nanos: -500000000}, {Nanos: 1, CurrencyCode: "USD"}},
+			&Money{Units: -2, Nanos: -499999999, CurrencyCode: "USD"}, nil,
+		},
+		{
+			[]*Money{{Units: math.MaxInt64}, {Units: 1}, {Units: -1}},
+			&Money{Units: math.MaxInt64}, nil,
+		},
+		{nil, &Money{}, nil},
+		{[]*Money{{Units: math.MaxInt64}, {Units: 1}}, nil, ErrOverflow},
+		{[]*Money{{Units: 1, CurrencyCode: "USD"}, {Units: 1, CurrencyCode: "EUR"}}, nil, ErrMismatchingCurrency},
+		{[]*Money{{Units: 1, CurrencyCode: "USD"}, {Units: 1, Nanos: -1, CurrencyCode: "USD"}}, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := Sum(v.items)
+		if err != v.err {
+			t.Errorf("%v: got error %v expected %v", v.items, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v: got %v expected %v", v.items, res, v.expected)
+		}
+	}
+}
+
+func TestSumContext(t *testing.T) {
+	items := make([]*Money, 10*sumCheckInterval)
+	for i := range items {
+		items[i] = &Money{Nanos: 100000000, CurrencyCode: "USD"}
+	}
+
+	res, err := SumContext(context.Background(), items)
+	expected := &Money{Units: sumCheckInterval, CurrencyCode: "USD"}
+	if err != nil || *res != *expected {
+		t.Errorf("got %v, %v expected %v", res, err, expected)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	countdown := &countdownContext{Context: ctx, cancel: cancel, n: 3}
+	if res, err := SumContext(countdown, items); err != context.Canceled {
+		t.Errorf("got %v, %v expected error %v", res, err, context.Canceled)
+	}
+	if countdown.n != 0 {
+		t.Errorf("stopped after %d more checks expected none", -countdown.n)
+	}
+
+	items[5] = &Money{Units: 1, CurrencyCode: "EUR"}
+	if _, err := SumContext(context.Background(), items); err != ErrMismatchingCurrency {
+		t.Errorf("got error %v expected %v", err, ErrMismatchingCurrency)
+	}
+}
+
+func TestAbsDiff(t *testing.T) {
+	cases := []struct {
+		a, b     *Money
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 10, Nanos: 500000000, CurrencyCode: "USD"}, &Money{Units: 3, Nanos: 750000000, CurrencyCode: "USD"}, &Money{Units: 6, Nanos: 750000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 3, Nanos: 750000000, CurrencyCode: "USD"}, &Money{Units: 10, Nanos: 500000000, CurrencyCode: "USD"}, &Money{Units: 6, Nanos: 750000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 5, CurrencyCode: "USD"}, &Money{Units: 5, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, nil},
+		{&Money{Units: -2, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, &Money{Units: 5, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 5, CurrencyCode: "USD"}, &Money{Units: 5, CurrencyCode: "EUR"}, nil, ErrMismatchingCurrency},
+		{&Money{Units: 5, Nanos: -1}, &Money{Units: 5}, nil, ErrInvalidValue},
+		{&Money{Units: math.MinInt64}, &Money{Units: 1}, nil, ErrOverflow},
+	}
+
+	for _, v := range cases {
+		res, err := AbsDiff(v.a, v.b)
+		if err != v.err {
+			t.Errorf("AbsDiff(%v, %v): got error %v expected %v", v.a, v.b, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("AbsDiff(%v, %v): got %v expected %v", v.a, v.b, res, v.expected)
+		}
+	}
+}
+
+func TestSumPositiveSumNegative(t *testing.T) {
+	items := []*Money{
+		{Units: 10, Nanos: 500000000, CurrencyCode: "USD"},
+		{Units: -3, Nanos: -250000000, CurrencyCode: "USD"},
+		nil,
+		{CurrencyCode: "USD"},
+		{Nanos: 1, CurrencyCode: "USD"},
+		{Units: -1, CurrencyCode: "USD"},
+	}
+
+	pos, err := SumPositive(items)
+	if expected := (&Money{Units: 10, Nanos: 500000001, CurrencyCode: "USD"}); err != nil || *pos != *expected {
+		t.Errorf("SumPositive: got %v, %v expected %v", pos, err, expected)
+	}
+	neg, err := SumNegative(items)
+	if expected := (&Money{Units: -4, Nanos: -250000000, CurrencyCode: "USD"}); err != nil || *neg != *expected {
+		t.Errorf("SumNegative: got %v, %v expected %v", neg, err, expected)
+	}
+
+	if res, err := SumNegative(items[:1]); err != nil || *res != (Money{CurrencyCode: "USD"}) {
+		t.Errorf("SumNegative without negative items: got %v, %v expected USD 0", res, err)
+	}
+	mixed := append(items, &Money{Units: -1, CurrencyCode: "EUR"})
+	if _, err := SumPositive(mixed); err != ErrMismatchingCurrency {
+		t.Errorf("got error %v expected %v", err, ErrMismatchingCurrency)
+	}
+	invalid := append(items, &Money{Units: 1, Nanos: -1, CurrencyCode: "USD"})
+	if _, err := SumNegative(invalid); err != ErrInvalidValue {
+		t.Errorf("got error %v expected %v", err, ErrInvalidValue)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	cases := []struct {
+		a, b     *Money
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, &Money{Units: 5, Nanos: 870000000, CurrencyCode: "USD"}, &Money{Units: 25, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, Nanos: 999999999, CurrencyCode: "USD"}, &Money{Nanos: 1, CurrencyCode: "USD"}, &Money{Units: 2, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, Nanos: 200000000, CurrencyCode: "USD"}, &Money{Units: -2, Nanos: -500000000, CurrencyCode: "USD"}, &Money{Units: -1, Nanos: -300000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -1, Nanos: -600000000}, &Money{Units: -1, Nanos: -600000000}, &Money{Units: -3, Nanos: -200000000}, nil},
+		{&Money{Units: 10}, &Money{Units: 3, CurrencyCode: "USD"}, &Money{Units: 13, CurrencyCode: "USD"}, nil},
+		{nil, &Money{Units: 3, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "EUR"}, nil, ErrMismatchingCurrency},
+		{&Money{Units: 10, Nanos: -1, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "USD"}, nil, ErrInvalidValue},
+		{&Money{Units: math.MaxInt64, Nanos: 999999999}, &Money{Nanos: 1}, nil, ErrOverflow},
+	}
+
+	for _, v := range cases {
+		res, err := Add(v.a, v.b)
+		if err != v.err {
+			t.Errorf("%v + %v: got error %v expected %v", v.a, v.b, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v + %v: got %v expected %v", v.a, v.b, res, v.expected)
+		}
+	}
+}
+
+func TestSub(t *testing.T) {
+	cases := []struct {
+		a, b     *Money
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 25, CurrencyCode: "USD"}, &Money{Units: 5, Nanos: 870000000, CurrencyCode: "USD"}, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, Nanos: 200000000, CurrencyCode: "USD"}, &Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, &Money{Units: -1, Nanos: -300000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, CurrencyCode: "USD"}, &Money{Units: 1, Nanos: 1, CurrencyCode: "USD"}, &Money{Nanos: -1, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -1, Nanos: -500000000}, &Money{Units: -3}, &Money{Units: 1, Nanos: 500000000}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, nil, &Money{Units: 10, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 3, CurrencyCode: "EUR"}, nil, ErrMismatchingCurrency},
+		{&Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: -3, Nanos: 1, CurrencyCode: "USD"}, nil, ErrInvalidValue},
+		{&Money{Units: math.MinInt64, Nanos: -999999999}, &Money{Nanos: 1}, nil, ErrOverflow},
+	}
+
+	for _, v := range cases {
+		res, err := Sub(v.a, v.b)
+		if err != v.err {
+			t.Errorf("%v - %v: got error %v expected %v", v.a, v.b, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v - %v: got %v expected %v", v.a, v.b, res, v.expected)
+		}
+		if res != nil && !IsValid(res) {
+			t.Errorf("%v - %v: got invalid %v", v.a, v.b, res)
+		}
+	}
+}
+
+func TestWouldOverflowAdd(t *testing.T) {
+	max := &Money{Units: math.MaxInt64, Nanos: 999999999}
+	min := &Money{Units: math.MinInt64, Nanos: -999999999}
+	cases := []struct {
+		a, b     *Money
+		expected bool
+	}{
+		{max, &Money{Nanos: 1}, true},
+		{max, &Money{Units: 1}, true},
+		{&Money{Units: math.MaxInt64}, &Money{Nanos: 999999999}, false},
+		{&Money{Units: math.MaxInt64, Nanos: 500000000}, &Money{Nanos: 500000000}, true},
+		{min, &Money{Nanos: -1}, true},
+		{min, max, false},
+		{max, nil, false},
+		{&Money{Units: 19, Nanos: 130000000}, &Money{Units: 5}, false},
+	}
+
+	for _, v := range cases {
+		if res := WouldOverflowAdd(v.a, v.b); res != v.expected {
+			t.Errorf("WouldOverflowAdd(%v, %v) got:%v expected:%v", v.a, v.b, res, v.expected)
+		}
+		_, err := Add(v.a, v.b)
+		if (err == ErrOverflow) != v.expected {
+			t.Errorf("Add(%v, %v) got error %v", v.a, v.b, err)
+		}
+	}
+}
+
+func TestSumBy(t *testing.T) {
+	items := []*Money{
+		{Units: 10, Nanos: 500000000, CurrencyCode: "USD"},
+		{Units: -3, CurrencyCode: "EUR"},
+		nil,
+		{Units: -2, Nanos: -250000000, CurrencyCode: "USD"},
+		{Units: 7, CurrencyCode: "EUR"},
+	}
+
+	byCurrency, err := SumBy(items, (*Money).GetCurrencyCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]Money{
+		"USD": {Units: 8, Nanos: 250000000, CurrencyCode: "USD"},
+		"EUR": {Units: 4, CurrencyCode: "EUR"},
+	}
+	if len(byCurrency) != len(expected) {
+		t.Errorf("got %v expected %v", byCurrency, expected)
+	}
+	for k, m := range expected {
+		if byCurrency[k] == nil || *byCurrency[k] != m {
+			t.Errorf("%s: got %v expected %v", k, byCurrency[k], m)
+		}
+	}
+
+	usd := []*Money{items[0], items[3], {Units: 1, CurrencyCode: "USD"}}
+	bySign, err := SumBy(usd, IsNegative)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bySign) != 2 || *bySign[false] != (Money{Units: 11, Nanos: 500000000, CurrencyCode: "USD"}) ||
+		*bySign[true] != (Money{Units: -2, Nanos: -250000000, CurrencyCode: "USD"}) {
+		t.Errorf("got %v", bySign)
+	}
+
+	if _, err := SumBy(items, IsNegative); !errors.Is(err, ErrMismatchingCurrency) {
+		t.Errorf("got error %v expected %v", err, ErrMismatchingCurrency)
+	}
+}
+
+func TestSubFloorZero(t *testing.T) {
+	cases := []struct {
+		a, b     *Money
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 10, Nanos: 500000000, CurrencyCode: "USD"}, &Money{Units: 3, Nanos: 750000000, CurrencyCode: "USD"}, &Money{Units: 6, Nanos: 750000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 5, CurrencyCode: "USD"}, &Money{Units: 5, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, nil},
+		{&Money{Units: 5, CurrencyCode: "USD"}, &Money{Units: 5, Nanos: 1, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, nil},
+		{&Money{Units: -2, CurrencyCode: "USD"}, &Money{Units: -3, CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 5, CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "EUR"}, nil, ErrMismatchingCurrency},
+		{&Money{Units: 5, Nanos: -1}, &Money{Units: 1}, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := SubFloorZero(v.a, v.b)
+		if err != v.err {
+			t.Errorf("SubFloorZero(%v, %v): got error %v expected %v", v.a, v.b, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("SubFloorZero(%v, %v): got %v expected %v", v.a, v.b, res, v.expected)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// AssertEqual fails t when got and want are not Equal, reporting them as a
+// diff of the amount and of each field that differs.
+func AssertEqual(t testing.TB, got, want *Money) {
+	t.Helper()
+	if Equals(got, want) {
+		return
+	}
+	t.Errorf("%s", moneyDiff(got, want))
+}
+
+// moneyDiff formats the difference between got and want with want on the
+// "-" lines and got on the "+" lines.
+func moneyDiff(got, want *Money) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Money mismatch (-want +got):\n-\t%v\n+\t%v", want, got)
+	if got == nil || want == nil {
+		return b.String()
+	}
+	if got.CurrencyCode != want.CurrencyCode {
+		fmt.Fprintf(&b, "\n  currency: -%q +%q", want.CurrencyCode, got.CurrencyCode)
+	}
+	if got.Units != want.Units {
+		fmt.Fprintf(&b, "\n  units: -%d +%d", want.Units, got.Units)
+	}
+	if got.Nanos != want.Nanos {
+		fmt.Fprintf(&b, "\n  nanos: -%d +%d", want.Nanos, got.Nanos)
+	}
+	return b.String()
+}
+
+// fakeTB records the calls AssertEqual makes instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	helper bool
+	errors []string
+}
+
+func (f *fakeTB) Helper() { f.helper = true }
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertEqual(t *testing.T) {
+	cases := []struct {
+		got, want *Money
+		expected  string
+	}{
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, ""},
+		{&Money{Nanos: 1000000000, CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "USD"}, ""},
+		{nil, nil, ""},
+		{
+			&Money{Units: 19, Nanos: 140000000, CurrencyCode: "USD"}, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"},
+			"Money mismatch (-want +got):\n-\tUSD 19.13\n+\tUSD 19.14\n  nanos: -130000000 +140000000",
+		},
+		{
+			&Money{Units: 5, CurrencyCode: "EUR"}, &Money{Units: 6, CurrencyCode: "USD"},
+			"Money mismatch (-want +got):\n-\tUSD 6\n+\tEUR 5\n  currency: -\"USD\" +\"EUR\"\n  units: -6 +5",
+		},
+		{nil, &Money{Units: 1}, "Money mismatch (-want +got):\n-\t1\n+\t<nil>"},
+	}
+
+	for _, v := range cases {
+		tb := &fakeTB{}
+		AssertEqual(tb, v.got, v.want)
+		if !tb.helper {
+			t.Errorf("%v, %v: Helper was not called", v.got, v.want)
+		}
+		res := strings.Join(tb.errors, "\n")
+		if res != v.expected {
+			t.Errorf("%v, %v: got:%q expected:%q", v.got, v.want, res, v.expected)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+// Calc chains arithmetic on a Money, carrying the first error through so
+// that a pipeline can be checked once at the end:
+//
+//	total, err := NewCalc(price).Mul(1.2).Sub(discount).Result()
+//
+// Once an operation fails the remaining ones are skipped. A Calc is a value
+// and each operation returns a new one, leaving the receiver unchanged.
+type Calc struct {
+	m   *Money
+	err error
+}
+
+// NewCalc starts a calculation from m.
+func NewCalc(m *Money) Calc {
+	return Calc{m: m}
+}
+
+// Add adds other to the running value.
+func (c Calc) Add(other *Money) Calc {
+	if c.err != nil {
+		return c
+	}
+	m, err := Add(c.m, other)
+	return Calc{m: m, err: err}
+}
+
+// Sub subtracts other from the running value.
+func (c Calc) Sub(other *Money) Calc {
+	if c.err != nil {
+		return c
+	}
+	m, err := Sub(c.m, other)
+	return Calc{m: m, err: err}
+}
+
+// Mul multiplies the running value by r with Mul.
+func (c Calc) Mul(r float64) Calc {
+	if c.err != nil {
+		return c
+	}
+	m, err := Mul(c.m, r)
+	return Calc{m: m, err: err}
+}
+
+// Result returns the value computed so far, or the first error encountered.
+func (c Calc) Result() (*Money, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.m, nil
+}
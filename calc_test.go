@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestCalc(t *testing.T) {
+	price := &Money{Units: 19, CurrencyCode: "USD"}
+	discount := &Money{Units: 2, Nanos: 800000000, CurrencyCode: "USD"}
+	shipping := &Money{Units: 4, Nanos: 990000000, CurrencyCode: "USD"}
+
+	res, err := NewCalc(price).Mul(1.2).Sub(discount).Add(shipping).Result()
+	expected := &Money{Units: 24, Nanos: 990000000, CurrencyCode: "USD"}
+	if err != nil || *res != *expected {
+		t.Errorf("got %v, %v expected %v", res, err, expected)
+	}
+	if *price != (Money{Units: 19, CurrencyCode: "USD"}) {
+		t.Errorf("price was modified: %v", price)
+	}
+}
+
+func TestCalcShortCircuits(t *testing.T) {
+	price := &Money{Units: 19, CurrencyCode: "USD"}
+	euros := &Money{Units: 1, CurrencyCode: "EUR"}
+
+	cases := []struct {
+		name string
+		calc Calc
+		err  error
+	}{
+		{"mismatched currency", NewCalc(price).Sub(euros).Mul(-1).Add(&Money{Units: 1, Nanos: -1}), ErrMismatchingCurrency},
+		{"invalid multiplier", NewCalc(price).Mul(-1).Sub(euros), ErrInvalidMultiplierProvided},
+		{"invalid value", NewCalc(&Money{Units: 1, Nanos: -1}).Add(price).Mul(2), ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := v.calc.Result()
+		if err != v.err || res != nil {
+			t.Errorf("%s: got %v, %v expected error %v", v.name, res, err, v.err)
+		}
+	}
+}
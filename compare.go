@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/subtle"
+	"hash/fnv"
+	"strings"
+)
+
+// Compare returns -1, 0 or +1 depending on whether a is less than, equal to
+// or greater than b. Only the amounts are compared, currency codes are
+// ignored. A nil value is less than any non-nil value. Compare is exempt from
+// the empty-currency policy of Add, Sub and Sum because it has no way to
+// report mismatching currencies; use OrderMoney to tell currencies apart or
+// Sub to compare with that policy.
+func Compare(a, b *Money) int {
+	if a == nil || b == nil {
+		switch {
+		case a == b:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return toNanos(a).Cmp(toNanos(b))
+}
+
+// OrderMoney orders a and b for sorting, returning -1, 0 or +1. It compares
+// the amounts like Compare and breaks ties by currency code, so it is a total
+// order over valid values that is consistent with Equals: nil sorts first and
+// OrderMoney(a, b) == 0 exactly when Equals(a, b).
+func OrderMoney(a, b *Money) int {
+	if c := Compare(a, b); c != 0 || a == nil || b == nil {
+		return c
+	}
+	return strings.Compare(a.CurrencyCode, b.CurrencyCode)
+}
+
+// CompareWithNormalizer is like Compare but passes both values through
+// normalize first, e.g. to convert an informal sub-denomination such as
+// cents into its base currency. A nil normalize compares a and b as is.
+func CompareWithNormalizer(a, b *Money, normalize func(*Money) *Money) int {
+	if normalize != nil {
+		a, b = normalize(a), normalize(b)
+	}
+	return Compare(a, b)
+}
+
+// Equals reports whether a and b hold the same amount in the same currency.
+// Amounts are compared in their canonical form, so a value that still needs
+// a carry, such as {Nanos: 1000000000}, equals {Units: 1}. Two nil values
+// are equal. Unlike arithmetic, an empty currency code only equals another
+// empty code, which keeps Equals transitive and consistent with Key. This is
+// a deliberate exception to the empty-code policy of Add, Sub and Sum; to
+// compare under that policy, check that Sub(a, b) succeeds and IsZero.
+func Equals(a, b *Money) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.CurrencyCode == b.CurrencyCode && toNanos(a).Cmp(toNanos(b)) == 0
+}
+
+// EqualsConstantTime reports the same as Equals but takes time independent
+// of where a and b differ, for comparisons whose timing must not leak
+// information, such as checking a signed amount. Only the lengths of the
+// currency codes and whether a value is nil may affect the timing.
+func EqualsConstantTime(a, b *Money) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	same := subtle.ConstantTimeCompare(constantTimeBytes(a), constantTimeBytes(b))
+	same &= subtle.ConstantTimeCompare([]byte(a.CurrencyCode), []byte(b.CurrencyCode))
+	return same == 1
+}
+
+// constantTimeBytes returns the canonical amount of m as a sign byte
+// followed by its magnitude in nanos in a fixed number of bytes, enough for
+// any Money.
+func constantTimeBytes(m *Money) []byte {
+	n := toNanos(m)
+	buf := make([]byte, 17)
+	if n.Sign() < 0 {
+		buf[0] = 1
+	}
+	n.Abs(n).FillBytes(buf[1:])
+	return buf
+}
+
+// EqualsString reports whether m equals the amount written in decimal,
+// parsed with Parse in the currency of m.
+func EqualsString(m *Money, decimal string) (bool, error) {
+	parsed, err := Parse(decimal, m.GetCurrencyCode())
+	if err != nil {
+		return false, err
+	}
+	return Equals(m, parsed), nil
+}
+
+// Key returns a canonical string for x that can be used as a map key: two
+// values that are Equals have the same Key. A nil Money has an empty key.
+func (x *Money) Key() string {
+	if x == nil {
+		return ""
+	}
+	return x.CurrencyCode + ":" + decimalString(x)
+}
+
+// NumericEquals reports whether a and b hold the same amount. Unlike Equals
+// it only looks at the numbers: currency codes are ignored entirely, so
+// {Units: 1, CurrencyCode: "USD"} and {Units: 1, CurrencyCode: "EUR"} are
+// numerically equal. Two nil values are equal.
+func NumericEquals(a, b *Money) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return toNanos(a).Cmp(toNanos(b)) == 0
+}
+
+// Checksum returns a 64-bit FNV-1a hash of the Key of x, suitable for
+// deduplication. Values that are Equals hash identically. A nil Money hashes
+// to 0.
+func (x *Money) Checksum() uint64 {
+	if x == nil {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(x.Key()))
+	return h.Sum64()
+}
+
+// Reconcile compares a computed amount with the expected one. It returns the
+// signed difference got - want in nanos and whether its magnitude is within
+// toleranceNanos.
+func Reconcile(got, want *Money, toleranceNanos int32) (bool, int64, error) {
+	if !IsValid(got) || !IsValid(want) {
+		return false, 0, ErrInvalidValue
+	}
+	if _, err := matchCurrency(got.GetCurrencyCode(), want.GetCurrencyCode()); err != nil {
+		return false, 0, err
+	}
+	diff := toNanos(got)
+	diff.Sub(diff, toNanos(want))
+	if !diff.IsInt64() {
+		return false, 0, ErrOverflow
+	}
+	d := diff.Int64()
+	return -int64(toleranceNanos) <= d && d <= int64(toleranceNanos), d, nil
+}
+
+// FindDuplicates groups the items that are Equals to one another and returns
+// the groups with more than one member, in order of first appearance. Nil
+// items are ignored.
+func FindDuplicates(items []*Money) [][]*Money {
+	index := make(map[string]int)
+	var groups [][]*Money
+	for _, m := range items {
+		if m == nil {
+			continue
+		}
+		k := m.Key()
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], m)
+	}
+
+	var res [][]*Money
+	for _, g := range groups {
+		if len(g) > 1 {
+			res = append(res, g)
+		}
+	}
+	return res
+}
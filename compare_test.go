@@ -0,0 +1,286 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		a, b     *Money
+		expected int
+	}{
+		{&Money{Units: 1}, &Money{Units: 2}, -1},
+		{&Money{Units: 2}, &Money{Units: 1}, 1},
+		{&Money{Units: 1, Nanos: 500000000}, &Money{Units: 1, Nanos: 500000000}, 0},
+		{&Money{Units: -1, Nanos: -1}, &Money{Units: -1}, -1},
+		{nil, &Money{Units: -5}, -1},
+		{&Money{}, nil, 1},
+		{nil, nil, 0},
+		// Currency codes play no part, empty or not.
+		{&Money{Units: 1, CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "EUR"}, 0},
+		{&Money{Units: 1, CurrencyCode: "USD"}, &Money{Units: 2}, -1},
+	}
+
+	for _, v := range cases {
+		if res := Compare(v.a, v.b); res != v.expected {
+			t.Errorf("Compare(%v, %v) got:%d expected:%d", v.a, v.b, res, v.expected)
+		}
+	}
+}
+
+func TestCompareWithNormalizer(t *testing.T) {
+	// cents converts the informal "USc" code into USD.
+	cents := func(m *Money) *Money {
+		if m.GetCurrencyCode() != "USc" {
+			return m
+		}
+		return FromInt64(m.GetUnits(), 100, "USD")
+	}
+
+	a := &Money{Units: 1913, CurrencyCode: "USc"}
+	b := &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}
+	if res := CompareWithNormalizer(a, b, cents); res != 0 {
+		t.Errorf("got:%d expected:0", res)
+	}
+	if res := CompareWithNormalizer(a, b, nil); res != 1 {
+		t.Errorf("without normalizer got:%d expected:1", res)
+	}
+}
+
+func TestEquals(t *testing.T) {
+	usd := &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}
+	cases := []struct {
+		a, b          *Money
+		equals        bool
+		numericEquals bool
+	}{
+		{usd, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, true, true},
+		{usd, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "EUR"}, false, true},
+		{usd, &Money{Units: 19, Nanos: 140000000, CurrencyCode: "USD"}, false, false},
+		{usd, nil, false, false},
+		{nil, nil, true, true},
+		{&Money{Units: 19, Nanos: 130000000}, &Money{Units: 19, Nanos: 130000000}, true, true},
+		// Equals keeps treating an empty code as distinct, unlike arithmetic.
+		{usd, &Money{Units: 19, Nanos: 130000000}, false, true},
+		{&Money{Units: 19, Nanos: 130000000}, usd, false, true},
+	}
+
+	for _, v := range cases {
+		if res := Equals(v.a, v.b); res != v.equals {
+			t.Errorf("Equals(%v, %v) got:%v expected:%v", v.a, v.b, res, v.equals)
+		}
+		if res := NumericEquals(v.a, v.b); res != v.numericEquals {
+			t.Errorf("NumericEquals(%v, %v) got:%v expected:%v", v.a, v.b, res, v.numericEquals)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	a := &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}
+	if a.Checksum() != (&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}).Checksum() {
+		t.Errorf("equal values have different checksums")
+	}
+	if (&Money{Units: 1, CurrencyCode: "USD"}).Checksum() != (&Money{Units: 0, Nanos: 1000000000, CurrencyCode: "USD"}).Checksum() {
+		t.Errorf("equivalent representations have different checksums")
+	}
+
+	others := []*Money{
+		{Units: 19, Nanos: 130000000, CurrencyCode: "EUR"},
+		{Units: 19, Nanos: 130000001, CurrencyCode: "USD"},
+		{Units: -19, Nanos: -130000000, CurrencyCode: "USD"},
+		{Units: 19, Nanos: 130000000},
+	}
+	for _, v := range others {
+		if v.Checksum() == a.Checksum() {
+			t.Errorf("%v has the same checksum as %v", v, a)
+		}
+	}
+
+	var nilMoney *Money
+	if nilMoney.Checksum() != 0 {
+		t.Errorf("nil checksum got:%d expected:0", nilMoney.Checksum())
+	}
+}
+
+func TestEqualsString(t *testing.T) {
+	m := &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}
+	cases := []struct {
+		decimal  string
+		expected bool
+		err      error
+	}{
+		{"19.13", true, nil},
+		{"19.130", true, nil},
+		{"19.14", false, nil},
+		{"-19.13", false, nil},
+		{"19,13", false, ErrInvalidDecimal},
+	}
+
+	for _, v := range cases {
+		res, err := EqualsString(m, v.decimal)
+		if res != v.expected || err != v.err {
+			t.Errorf("%q: got %v, %v expected %v, %v", v.decimal, res, err, v.expected, v.err)
+		}
+	}
+}
+
+func TestEqualsCanonicalForm(t *testing.T) {
+	cases := []struct {
+		a, b *Money
+	}{
+		{&Money{Units: 1, CurrencyCode: "USD"}, &Money{Units: 0, Nanos: 1000000000, CurrencyCode: "USD"}},
+		{&Money{Units: -1, CurrencyCode: "USD"}, &Money{Units: 0, Nanos: -1000000000, CurrencyCode: "USD"}},
+		{&Money{Units: 0, Nanos: 500000000, CurrencyCode: "USD"}, &Money{Units: 1, Nanos: -500000000, CurrencyCode: "USD"}},
+	}
+
+	for _, v := range cases {
+		if !Equals(v.a, v.b) || !NumericEquals(v.a, v.b) {
+			t.Errorf("%+v and %+v should be equal", *v.a, *v.b)
+		}
+		if v.a.Key() != v.b.Key() {
+			t.Errorf("%+v and %+v have different keys %q and %q", *v.a, *v.b, v.a.Key(), v.b.Key())
+		}
+	}
+
+	if Equals(&Money{Units: 1, CurrencyCode: "USD"}, &Money{Units: 0, Nanos: 1000000000, CurrencyCode: "EUR"}) {
+		t.Errorf("values in different currencies should not be equal")
+	}
+	if key := (&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}).Key(); key != "USD:19.13" {
+		t.Errorf("got key %q expected %q", key, "USD:19.13")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	want := &Money{Units: 22, Nanos: 956000000, CurrencyCode: "USD"}
+	cases := []struct {
+		got       *Money
+		tolerance int32
+		ok        bool
+		diff      int64
+		err       error
+	}{
+		{&Money{Units: 22, Nanos: 956000000, CurrencyCode: "USD"}, 0, true, 0, nil},
+		{&Money{Units: 22, Nanos: 956000001, CurrencyCode: "USD"}, 1, true, 1, nil},
+		{&Money{Units: 22, Nanos: 955999999, CurrencyCode: "USD"}, 1, true, -1, nil},
+		{&Money{Units: 22, Nanos: 955999998, CurrencyCode: "USD"}, 1, false, -2, nil},
+		{&Money{Units: 23, Nanos: 0, CurrencyCode: "USD"}, 1000, false, 44000000, nil},
+		{&Money{Units: 22, Nanos: 956000000, CurrencyCode: "EUR"}, 0, false, 0, ErrMismatchingCurrency},
+		{&Money{Units: math.MaxInt64, CurrencyCode: "USD"}, 0, false, 0, ErrOverflow},
+	}
+
+	for _, v := range cases {
+		ok, diff, err := Reconcile(v.got, want, v.tolerance)
+		if ok != v.ok || diff != v.diff || err != v.err {
+			t.Errorf("%v vs %v: got %v, %d, %v expected %v, %d, %v", v.got, want, ok, diff, err, v.ok, v.diff, v.err)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	a := &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}
+	b := &Money{Units: 5, CurrencyCode: "USD"}
+	c := &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}
+	d := &Money{Units: 19, Nanos: 130000000, CurrencyCode: "EUR"}
+
+	res := FindDuplicates([]*Money{a, b, nil, c, d, nil})
+	if len(res) != 1 || len(res[0]) != 2 || res[0][0] != a || res[0][1] != c {
+		t.Errorf("got %v expected [[%v %v]]", res, a, c)
+	}
+
+	if res := FindDuplicates([]*Money{a, b, d}); res != nil {
+		t.Errorf("got %v expected no duplicates", res)
+	}
+}
+
+func TestOrderMoneyTotalOrder(t *testing.T) {
+	samples := []*Money{
+		nil,
+		{Units: -2, Nanos: -500000000, CurrencyCode: "USD"},
+		{Units: 0, CurrencyCode: "USD"},
+		{Nanos: 1, CurrencyCode: "USD"},
+		{Units: 1, CurrencyCode: "EUR"},
+		{Units: 1, CurrencyCode: "USD"},
+		{Nanos: 1000000000, CurrencyCode: "USD"},
+		{Units: 19, Nanos: 130000000, CurrencyCode: "USD"},
+	}
+
+	for _, a := range samples {
+		if OrderMoney(a, a) != 0 {
+			t.Errorf("OrderMoney(%v, %v) != 0", a, a)
+		}
+		for _, b := range samples {
+			ab, ba := OrderMoney(a, b), OrderMoney(b, a)
+			if ab != -ba {
+				t.Errorf("antisymmetry: OrderMoney(%v, %v) = %d, OrderMoney(%v, %v) = %d", a, b, ab, b, a, ba)
+			}
+			if (ab == 0) != Equals(a, b) {
+				t.Errorf("OrderMoney(%v, %v) = %d disagrees with Equals", a, b, ab)
+			}
+			for _, c := range samples {
+				if ab <= 0 && OrderMoney(b, c) <= 0 && OrderMoney(a, c) > 0 {
+					t.Errorf("transitivity: %v <= %v <= %v but %v > %v", a, b, c, a, c)
+				}
+			}
+		}
+	}
+}
+
+type moneyHeap []*Money
+
+func (h moneyHeap) Len() int            { return len(h) }
+func (h moneyHeap) Less(i, j int) bool  { return OrderMoney(h[i], h[j]) < 0 }
+func (h moneyHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *moneyHeap) Push(x interface{}) { *h = append(*h, x.(*Money)) }
+func (h *moneyHeap) Pop() interface{} {
+	old := *h
+	m := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return m
+}
+
+func ExampleOrderMoney() {
+	h := &moneyHeap{
+		{Units: 19, Nanos: 130000000, CurrencyCode: "USD"},
+		{Units: 5, CurrencyCode: "USD"},
+		{Units: 5, CurrencyCode: "EUR"},
+		{Units: -1, CurrencyCode: "USD"},
+	}
+	heap.Init(h)
+	for h.Len() > 0 {
+		fmt.Println(heap.Pop(h))
+	}
+	// Output:
+	// USD -1
+	// EUR 5
+	// USD 5
+	// USD 19.13
+}
+
+func TestEqualsConstantTime(t *testing.T) {
+	samples := []*Money{
+		nil,
+		{},
+		{CurrencyCode: "USD"},
+		{Units: 1, CurrencyCode: "USD"},
+		{Nanos: 1000000000, CurrencyCode: "USD"},
+		{Units: 2, Nanos: -1000000000, CurrencyCode: "USD"},
+		{Units: 1, CurrencyCode: "EUR"},
+		{Units: -1, CurrencyCode: "USD"},
+		{Nanos: 1, CurrencyCode: "USD"},
+		{Nanos: -1, CurrencyCode: "USD"},
+		{Units: math.MaxInt64, Nanos: 999999999, CurrencyCode: "USD"},
+		{Units: math.MinInt64, Nanos: -999999999, CurrencyCode: "USD"},
+		{Units: 1, CurrencyCode: "USDX"},
+	}
+
+	for _, a := range samples {
+		for _, b := range samples {
+			if res, expected := EqualsConstantTime(a, b), Equals(a, b); res != expected {
+				t.Errorf("EqualsConstantTime(%+v, %+v) got:%v expected:%v", a, b, res, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CsvErrorMode controls how ReadCsv reacts to rows it can't parse.
+type CsvErrorMode int
+
+const (
+	// FailFast stops reading at the first bad row.
+	FailFast CsvErrorMode = iota
+	// CollectAll skips bad rows and reports all of them at the end.
+	CollectAll
+)
+
+// CsvOptions configures ReadCsv.
+type CsvOptions struct {
+	// Offset is the number of leading columns to skip before the input,
+	// rate and expected columns.
+	Offset int
+	// ErrorMode selects between FailFast and CollectAll.
+	ErrorMode CsvErrorMode
+	// ToleranceNanos is how far a computed result may be from the expected
+	// amount and still count as a match.
+	ToleranceNanos int32
+	// Failures, when set, receives the mismatching rows in CSV form as
+	// written by WriteCsv.
+	Failures io.Writer
+}
+
+// CsvRecord is a parsed row of a verification file: Input multiplied by
+// Rate is expected to give Expected.
+type CsvRecord struct {
+	Line int
+	// Fields holds the columns of the row as they were read.
+	Fields   []string
+	Input    *Money
+	Rate     float64
+	Expected *Money
+}
+
+// CsvRowError describes a row that couldn't be parsed.
+type CsvRowError struct {
+	Line int
+	Err  error
+}
+
+func (e *CsvRowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *CsvRowError) Unwrap() error {
+	return e.Err
+}
+
+// CsvErrors lists every bad row found in CollectAll mode.
+type CsvErrors []*CsvRowError
+
+func (e CsvErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, rowErr := range e {
+		msgs[i] = rowErr.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ReadCsv reads verification records from r. In FailFast mode the first bad
+// row is returned as a *CsvRowError. In CollectAll mode the valid records are
+// returned together with a CsvErrors holding every bad row.
+func ReadCsv(r io.Reader, opts CsvOptions) ([]CsvRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var records []CsvRecord
+	var rowErrs CsvErrors
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var rowErr *CsvRowError
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			rowErr = &CsvRowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		} else if err != nil {
+			return records, err
+		} else {
+			line, _ := cr.FieldPos(0)
+			record, err := parseCsvRecord(fields, opts.Offset)
+			if err != nil {
+				rowErr = &CsvRowError{Line: line, Err: err}
+			} else {
+				record.Line = line
+				records = append(records, record)
+			}
+		}
+
+		if rowErr != nil {
+			if opts.ErrorMode == FailFast {
+				return records, rowErr
+			}
+			rowErrs = append(rowErrs, rowErr)
+		}
+	}
+
+	if len(rowErrs) > 0 {
+		return records, rowErrs
+	}
+	return records, nil
+}
+
+// MismatchRow is a record whose computed result differs from the expected
+// amount.
+type MismatchRow struct {
+	CsvRecord
+	Got *Money
+}
+
+func (r MismatchRow) String() string {
+	return fmt.Sprintf("line %d: input=%v rate=%v got=%v want=%v", r.Line, r.Input, r.Rate, r.Got, r.Expected)
+}
+
+// VerifyCsv multiplies the input of every record read from r by its rate and
+// writes a line to w for each result that doesn't match the expected amount
+// within opts.ToleranceNanos. The mismatching rows are also returned and,
+// when opts.Failures is set, written to it with WriteCsv.
+func VerifyCsv(r io.Reader, w io.Writer, opts CsvOptions) ([]MismatchRow, error) {
+	_, _, mismatches, err := verifyCsv(r, w, opts)
+	return mismatches, err
+}
+
+// RunCsvVerification verifies the CSV file at filePath, whose rows hold the
+// input, rate and expected columns, like VerifyCsv does. It returns how many
+// rows matched within toleranceNanos, how many didn't, and the mismatching
+// rows. Rows that can't be parsed are not counted and are reported through
+// err as CsvErrors, after the rest of the file has been processed.
+func RunCsvVerification(filePath string, toleranceNanos int32) (passed int, failed int, rows []MismatchRow, err error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer f.Close()
+	return verifyCsv(f, io.Discard, CsvOptions{ErrorMode: CollectAll, ToleranceNanos: toleranceNanos})
+}
+
+// verifyCsv implements VerifyCsv and RunCsvVerification.
+func verifyCsv(r io.Reader, w io.Writer, opts CsvOptions) (int, int, []MismatchRow, error) {
+	records, err := ReadCsv(r, opts)
+	passed, mismatches := verifyRecords(records, w, opts.ToleranceNanos)
+
+	if opts.Failures != nil && len(mismatches) > 0 {
+		if writeErr := WriteCsv(opts.Failures, mismatches); writeErr != nil && err == nil {
+			err = writeErr
+		}
+	}
+	return passed, len(records) - passed, mismatches, err
+}
+
+// verifyRecords checks each record's product against its expected amount. It
+// returns how many matched within toleranceNanos and the rows that didn't,
+// writing a line to w for each failure. Records whose product can't be
+// computed fail without a row.
+func verifyRecords(records []CsvRecord, w io.Writer, toleranceNanos int32) (int, []MismatchRow) {
+	passed := 0
+	var mismatches []MismatchRow
+	for _, record := range records {
+		got, err := Mulv2(record.Input, record.Rate)
+		if err != nil {
+			fmt.Fprintf(w, "line %d: %v\n", record.Line, err)
+			continue
+		}
+		if ok, _, err := Reconcile(got, record.Expected, toleranceNanos); err != nil || !ok {
+			row := MismatchRow{CsvRecord: record, Got: got}
+			mismatches = append(mismatches, row)
+			fmt.Fprintln(w, row)
+			continue
+		}
+		passed++
+	}
+	return passed, mismatches
+}
+
+// WriteCsv writes rows in CSV form: the columns of each row as they were read
+// followed by the computed result.
+func WriteCsv(w io.Writer, rows []MismatchRow) error {
+	cw := csv.NewWriter(w)
+	for _, row := range rows {
+		record := make([]string, 0, len(row.Fields)+1)
+		record = append(record, row.Fields...)
+		if err := cw.Write(append(record, decimalString(row.Got))); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// parseCsvRecord converts the input, rate and expected columns found after
+// offset into a CsvRecord.
+func parseCsvRecord(fields []string, offset int) (CsvRecord, error) {
+	if len(fields) < offset+3 {
+		return CsvRecord{}, fmt.Errorf("expected at least %d columns, got %d", offset+3, len(fields))
+	}
+	input, err := Parse(fields[offset], "")
+	if err != nil {
+		return CsvRecord{}, fmt.Errorf("input %q: %w", fields[offset], err)
+	}
+	rate, err := strconv.ParseFloat(fields[offset+1], 64)
+	if err != nil {
+		return CsvRecord{}, fmt.Errorf("rate %q: %w", fields[offset+1], err)
+	}
+	expected, err := Parse(fields[offset+2], "")
+	if err != nil {
+		return CsvRecord{}, fmt.Errorf("expected %q: %w", fields[offset+2], err)
+	}
+
+	return CsvRecord{
+		Fields:   fields,
+		Input:    input,
+		Rate:     rate,
+		Expected: expected,
+	}, nil
+}
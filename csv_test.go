@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const csvWithTwoBadRows = `19,1.2,22.8
+abc,1.2,22.8
+0.7,15.1,10.57
+5,1.5
+`
+
+func TestReadCsvFailFast(t *testing.T) {
+	records, err := ReadCsv(strings.NewReader(csvWithTwoBadRows), CsvOptions{ErrorMode: FailFast})
+	var rowErr *CsvRowError
+	if !errors.As(err, &rowErr) {
+		t.Fatalf("expected a row error, got %v", err)
+	}
+	if rowErr.Line != 2 {
+		t.Errorf("got line %d expected 2", rowErr.Line)
+	}
+	if !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("got %v expected ErrInvalidDecimal", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("got %d records expected 1", len(records))
+	}
+}
+
+func TestReadCsvCollectAll(t *testing.T) {
+	records, err := ReadCsv(strings.NewReader(csvWithTwoBadRows), CsvOptions{ErrorMode: CollectAll})
+	var rowErrs CsvErrors
+	if !errors.As(err, &rowErrs) {
+		t.Fatalf("expected collected row errors, got %v", err)
+	}
+	if len(rowErrs) != 2 || rowErrs[0].Line != 2 || rowErrs[1].Line != 4 {
+		t.Errorf("got %v expected errors on lines 2 and 4", rowErrs)
+	}
+	if len(records) != 2 || records[0].Line != 1 || records[1].Line != 3 {
+		t.Fatalf("got %v expected records on lines 1 and 3", records)
+	}
+	if records[1].Rate != 15.1 || records[1].Expected.Units != 10 || records[1].Expected.Nanos != 570000000 {
+		t.Errorf("unexpected record %+v", records[1])
+	}
+}
+
+func TestReadCsvOffset(t *testing.T) {
+	records, err := ReadCsv(strings.NewReader("5,1100,0.0005,0.11,0.000055\n"), CsvOptions{Offset: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Input.Nanos != 500000 || records[0].Expected.Nanos != 55000 {
+		t.Errorf("unexpected records %+v", records)
+	}
+}
+
+func TestVerifyCsv(t *testing.T) {
+	input := "19.13,1.2,22.956\n19.13,1.2,22.96\n"
+	var out bytes.Buffer
+	rows, err := VerifyCsv(strings.NewReader(input), &out, CsvOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d mismatches expected 1", len(rows))
+	}
+
+	expected := "line 2: input=19.13 rate=1.2 got=22.956 want=22.96\n"
+	if out.String() != expected {
+		t.Errorf("got:%q expected:%q", out.String(), expected)
+	}
+
+	row := MismatchRow{
+		CsvRecord: CsvRecord{
+			Line:     42,
+			Input:    &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"},
+			Rate:     1.2,
+			Expected: &Money{Units: 22, Nanos: 960000000, CurrencyCode: "USD"},
+		},
+		Got: &Money{Units: 22, Nanos: 950000000, CurrencyCode: "USD"},
+	}
+	rows, err = VerifyCsv(strings.NewReader("19.13,1.2,22.956000001\n"), &out, CsvOptions{ToleranceNanos: 1})
+	if err != nil || len(rows) != 0 {
+		t.Errorf("one nano off should be within tolerance, got %v, %v", rows, err)
+	}
+
+	expected = "line 42: input=USD 19.13 rate=1.2 got=USD 22.95 want=USD 22.96"
+	if row.String() != expected {
+		t.Errorf("got:%q expected:%q", row.String(), expected)
+	}
+}
+
+func TestVerifyCsvFailures(t *testing.T) {
+	input := "a,b,19,1.2,22.8\na,b,19.13,1.2,22.96\n"
+	var out, failures bytes.Buffer
+	rows, err := VerifyCsv(strings.NewReader(input), &out, CsvOptions{Offset: 2, Failures: &failures})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d mismatches expected 1", len(rows))
+	}
+
+	expected := "a,b,19.13,1.2,22.96,22.956\n"
+	if failures.String() != expected {
+		t.Errorf("got:%q expected:%q", failures.String(), expected)
+	}
+}
+
+func TestRunCsvVerification(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "verify.csv")
+	data := "19,1.2,22.8\n19.13,1.2,22.96\n0.7,15.1,10.57\nabc,1.2,22.8\n10,1.5,15.000000001\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	passed, failed, rows, err := RunCsvVerification(path, 1)
+	var rowErrs CsvErrors
+	if !errors.As(err, &rowErrs) || len(rowErrs) != 1 || rowErrs[0].Line != 4 {
+		t.Errorf("got error %v expected a row error on line 4", err)
+	}
+	if passed != 3 || failed != 1 {
+		t.Errorf("got %d passed, %d failed expected 3 passed, 1 failed", passed, failed)
+	}
+	if len(rows) != 1 || rows[0].Line != 2 {
+		t.Errorf("got %v expected a mismatch on line 2", rows)
+	}
+
+	if _, _, _, err := RunCsvVerification(filepath.Join(t.TempDir(), "missing.csv"), 1); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: got error %v expected %v", err, os.ErrNotExist)
+	}
+}
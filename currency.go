@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// currencyExponents maps ISO 4217 currency codes to the number of decimal
+// places of their minor unit.
+var currencyExponents = map[string]int{
+	// Currencies without a minor unit.
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0,
+	"XPF": 0,
+	// Currencies with three decimal places.
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// Currencies with four decimal places.
+	"CLF": 4, "UYW": 4,
+	// Everything else uses cents.
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2, "AUD": 2,
+	"AWG": 2, "AZN": 2, "BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BMD": 2, "BND": 2,
+	"BOB": 2, "BOV": 2, "BRL": 2, "BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2,
+	"CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2, "CHW": 2, "CNY": 2, "COP": 2, "COU": 2,
+	"CRC": 2, "CUC": 2, "CUP": 2, "CVE": 2, "CZK": 2, "DKK": 2, "DOP": 2, "DZD": 2,
+	"EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2, "GBP": 2, "GEL": 2,
+	"GHS": 2, "GIP": 2, "GMD": 2, "GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2, "HTG": 2,
+	"HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "IRR": 2, "JMD": 2, "KES": 2, "KGS": 2,
+	"KHR": 2, "KPW": 2, "KYD": 2, "KZT": 2, "LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2,
+	"LSL": 2, "MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2, "MMK": 2, "MNT": 2, "MOP": 2,
+	"MRU": 2, "MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2, "MXV": 2, "MYR": 2, "MZN": 2,
+	"NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2, "NPR": 2, "NZD": 2, "PAB": 2, "PEN": 2,
+	"PGK": 2, "PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2,
+	"SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2,
+	"SLL": 2, "SOS": 2, "SRD": 2, "SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2,
+	"THB": 2, "TJS": 2, "TMT": 2, "TOP": 2, "TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2,
+	"UAH": 2, "USD": 2, "USN": 2, "UYU": 2, "UZS": 2, "VED": 2, "VES": 2, "WST": 2,
+	"XCD": 2, "XCG": 2, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWG": 2, "ZWL": 2,
+}
+
+// currencyExponent returns the number of decimal places of the minor unit of
+// currencyCode, or ErrUnknownCurrency.
+func currencyExponent(currencyCode string) (int, error) {
+	exp, ok := currencyExponents[currencyCode]
+	if !ok {
+		return 0, ErrUnknownCurrency
+	}
+	return exp, nil
+}
+
+// isValidCurrencyCode reports whether code looks like an ISO 4217 code, i.e.
+// three upper case ASCII letters.
+func isValidCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// MultiplierFor returns the number of minor units in one unit of
+// currencyCode, e.g. 100 for USD and 1 for JPY. It returns ErrUnknownCurrency
+// for codes that are not in ISO 4217.
+func MultiplierFor(currencyCode string) (int64, error) {
+	exp, err := currencyExponent(currencyCode)
+	if err != nil {
+		return 0, err
+	}
+	return pow10(exp), nil
+}
+
+// IsSettleable reports whether m is a whole number of minor units of its
+// currency, i.e. it carries no fraction of a cent for USD or of a yen for
+// JPY. It returns ErrUnknownCurrency for codes that are not in ISO 4217.
+func IsSettleable(m *Money) (bool, error) {
+	mult, err := MultiplierFor(m.GetCurrencyCode())
+	if err != nil {
+		return false, err
+	}
+	if !IsValid(m) {
+		return false, ErrInvalidValue
+	}
+	return m.GetNanos()%int32(nanosMod/mult) == 0, nil
+}
+
+// pow10 returns 10^n for 0 <= n <= 18.
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// ToCurrencyPrecision rounds m to the minor unit of its currency, with halves
+// rounded to even, so that the result is representable in that currency.
+func ToCurrencyPrecision(m *Money) (*Money, error) {
+	exp, err := currencyExponent(m.GetCurrencyCode())
+	if err != nil {
+		return nil, err
+	}
+	if !IsValid(m) {
+		return nil, ErrInvalidValue
+	}
+	step := big.NewInt(pow10(9 - exp))
+	n := roundQuo(toNanos(m), step, HalfEven)
+	return fromNanos(n.Mul(n, step), m.GetCurrencyCode())
+}
+
+// ToMinorUnits converts m to an integer number of minor units of its
+// currency, e.g. cents for USD, rounding any leftover nanos according to
+// mode. It returns ErrOverflow if the result doesn't fit in an int64.
+func ToMinorUnits(m *Money, mode RoundingMode) (int64, error) {
+	if !mode.valid() {
+		return 0, ErrInvalidRoundingMode
+	}
+	exp, err := currencyExponent(m.GetCurrencyCode())
+	if err != nil {
+		return 0, err
+	}
+	if !IsValid(m) {
+		return 0, ErrInvalidValue
+	}
+	minor := roundQuo(toNanos(m), big.NewInt(pow10(9-exp)), mode)
+	if !minor.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return minor.Int64(), nil
+}
+
+// RescaleMinorUnits converts an integer amount of minor units with fromExp
+// decimal places into one with toExp decimal places, e.g. 1913 cents with
+// exponent 2 into 191300 with exponent 4. Downscaling that would drop
+// nonzero digits returns ErrPrecisionExceeded; use RescaleMinorUnitsRound to
+// round instead. Exponents must be between 0 and 9.
+func RescaleMinorUnits(amount int64, fromExp, toExp int) (int64, error) {
+	res, err := RescaleMinorUnitsRound(amount, fromExp, toExp, TowardZero)
+	if err != nil {
+		return 0, err
+	}
+	if fromExp > toExp && amount%pow10(fromExp-toExp) != 0 {
+		return 0, ErrPrecisionExceeded
+	}
+	return res, nil
+}
+
+// RescaleMinorUnitsRound is like RescaleMinorUnits but rounds away digits
+// lost when downscaling according to mode.
+func RescaleMinorUnitsRound(amount int64, fromExp, toExp int, mode RoundingMode) (int64, error) {
+	if !mode.valid() {
+		return 0, ErrInvalidRoundingMode
+	}
+	if fromExp < 0 || fromExp > 9 || toExp < 0 || toExp > 9 {
+		return 0, ErrInvalidPlaces
+	}
+	if toExp >= fromExp {
+		res, ok := mulInt64(amount, pow10(toExp-fromExp))
+		if !ok {
+			return 0, ErrOverflow
+		}
+		return res, nil
+	}
+	return roundQuo(big.NewInt(amount), big.NewInt(pow10(fromExp-toExp)), mode).Int64(), nil
+}
+
+// SameCurrency returns the currency code shared by all non-nil items, or
+// ErrMismatchingCurrency if they differ. Items with an empty code match any
+// currency. It returns an empty code when no item has one.
+func SameCurrency(items []*Money) (string, error) {
+	code := ""
+	for _, m := range items {
+		if m == nil {
+			continue
+		}
+		var err error
+		if code, err = matchCurrency(code, m.CurrencyCode); err != nil {
+			return "", err
+		}
+	}
+	return code, nil
+}
+
+// ToMinorUnitsBatch converts every item to minor units of its own currency
+// with ToMinorUnits. It fails on the first item that can't be converted,
+// reporting its index.
+func ToMinorUnitsBatch(items []*Money, mode RoundingMode) ([]int64, error) {
+	res := make([]int64, len(items))
+	for i, m := range items {
+		minor, err := ToMinorUnits(m, mode)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		res[i] = minor
+	}
+	return res, nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMultiplierFor(t *testing.T) {
+	cases := []struct {
+		code     string
+		expected int64
+		err      error
+	}{
+		{"USD", 100, nil},
+		{"EUR", 100, nil},
+		{"JPY", 1, nil},
+		{"BHD", 1000, nil},
+		{"CLF", 10000, nil},
+		{"", 0, ErrUnknownCurrency},
+		{"usd", 0, ErrUnknownCurrency},
+		{"XYZ", 0, ErrUnknownCurrency},
+	}
+
+	for _, v := range cases {
+		res, err := MultiplierFor(v.code)
+		if err != v.err || res != v.expected {
+			t.Errorf("%q: got %d, %v expected %d, %v", v.code, res, err, v.expected, v.err)
+		}
+	}
+}
+
+func TestToCurrencyPrecision(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 1, Nanos: 125000000, CurrencyCode: "USD"}, &Money{Units: 1, Nanos: 120000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, Nanos: 135000000, CurrencyCode: "USD"}, &Money{Units: 1, Nanos: 140000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, Nanos: 125000001, CurrencyCode: "USD"}, &Money{Units: 1, Nanos: 130000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -1, Nanos: -125000000, CurrencyCode: "USD"}, &Money{Units: -1, Nanos: -120000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 0, Nanos: 995000000, CurrencyCode: "USD"}, &Money{Units: 1, Nanos: 0, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, Nanos: 234500000, CurrencyCode: "BHD"}, &Money{Units: 1, Nanos: 234000000, CurrencyCode: "BHD"}, nil},
+		{&Money{Units: 1, Nanos: 235500000, CurrencyCode: "BHD"}, &Money{Units: 1, Nanos: 236000000, CurrencyCode: "BHD"}, nil},
+		{&Money{Units: 7, Nanos: 500000000, CurrencyCode: "JPY"}, &Money{Units: 8, Nanos: 0, CurrencyCode: "JPY"}, nil},
+		{&Money{Units: 1, CurrencyCode: "XYZ"}, nil, ErrUnknownCurrency},
+		{&Money{Units: 1, Nanos: -1, CurrencyCode: "USD"}, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := ToCurrencyPrecision(v.input)
+		if err != v.err {
+			t.Errorf("%v: got error %v expected %v", v.input, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v: got %v expected %v", v.input, res, v.expected)
+		}
+	}
+}
+
+func TestToMinorUnits(t *testing.T) {
+	half := &Money{Units: 1, Nanos: 125000000, CurrencyCode: "USD"}
+	negativeHalf := &Money{Units: -1, Nanos: -125000000, CurrencyCode: "USD"}
+	cases := []struct {
+		input    *Money
+		mode     RoundingMode
+		expected int64
+		err      error
+	}{
+		{half, HalfUp, 113, nil},
+		{half, HalfDown, 112, nil},
+		{half, HalfEven, 112, nil},
+		{half, Floor, 112, nil},
+		{half, Ceiling, 113, nil},
+		{negativeHalf, HalfUp, -113, nil},
+		{negativeHalf, HalfDown, -112, nil},
+		{negativeHalf, HalfEven, -112, nil},
+		{negativeHalf, Floor, -113, nil},
+		{negativeHalf, Ceiling, -112, nil},
+		{&Money{Units: 1, Nanos: 135000000, CurrencyCode: "USD"}, HalfEven, 114, nil},
+		{&Money{Units: 19, Nanos: 500000000, CurrencyCode: "JPY"}, HalfEven, 20, nil},
+		{&Money{Units: 1, Nanos: 234500000, CurrencyCode: "BHD"}, HalfUp, 1235, nil},
+		{&Money{Units: math.MaxInt64, CurrencyCode: "USD"}, HalfUp, 0, ErrOverflow},
+		{&Money{Units: 1, CurrencyCode: "XYZ"}, HalfUp, 0, ErrUnknownCurrency},
+		{half, RoundingMode(-1), 0, ErrInvalidRoundingMode},
+	}
+
+	for _, v := range cases {
+		res, err := ToMinorUnits(v.input, v.mode)
+		if err != v.err || res != v.expected {
+			t.Errorf("%v %v: got %d, %v expected %d, %v", v.input, v.mode, res, err, v.expected, v.err)
+		}
+	}
+}
+
+func TestSameCurrency(t *testing.T) {
+	cases := []struct {
+		name     string
+		items    []*Money
+		expected string
+		err      error
+	}{
+		{"homogeneous", []*Money{{Units: 1, CurrencyCode: "USD"}, nil, {Units: 2, CurrencyCode: "USD"}}, "USD", nil},
+		{"mismatched", []*Money{{Units: 1, CurrencyCode: "USD"}, {Units: 2, CurrencyCode: "EUR"}}, "", ErrMismatchingCurrency},
+		{"all nil", []*Money{nil, nil}, "", nil},
+		{"all empty codes", []*Money{{Units: 1}, {Units: 2}}, "", nil},
+		{"empty code inherits", []*Money{{Units: 1}, {Units: 2, CurrencyCode: "USD"}, {Units: 3}}, "USD", nil},
+		{"empty code between mismatched", []*Money{{Units: 1, CurrencyCode: "USD"}, {Units: 2}, {Units: 3, CurrencyCode: "EUR"}}, "", ErrMismatchingCurrency},
+		{"empty", nil, "", nil},
+	}
+
+	for _, v := range cases {
+		res, err := SameCurrency(v.items)
+		if err != v.err || res != v.expected {
+			t.Errorf("%s: got %q, %v expected %q, %v", v.name, res, err, v.expected, v.err)
+		}
+	}
+}
+
+func TestToMinorUnitsBatch(t *testing.T) {
+	items := []*Money{
+		{Units: 19, Nanos: 130000000, CurrencyCode: "USD"},
+		{Units: 1500, CurrencyCode: "JPY"},
+		{Units: -2, Nanos: -505000000, CurrencyCode: "USD"},
+		{Units: 7, Nanos: 500000000, CurrencyCode: "JPY"},
+	}
+	res, err := ToMinorUnitsBatch(items, HalfEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int64{1913, 1500, -250, 8}
+	for i := range expected {
+		if res[i] != expected[i] {
+			t.Errorf("got %v expected %v", res, expected)
+			break
+		}
+	}
+
+	items = append(items, &Money{Units: 1, CurrencyCode: "XYZ"})
+	if _, err := ToMinorUnitsBatch(items, HalfEven); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("got %v expected ErrUnknownCurrency", err)
+	}
+	if _, err := ToMinorUnitsBatch([]*Money{{Units: math.MaxInt64, CurrencyCode: "USD"}}, HalfEven); !errors.Is(err, ErrOverflow) {
+		t.Errorf("got %v expected ErrOverflow", err)
+	}
+}
+
+func TestIsSettleable(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		expected bool
+		err      error
+	}{
+		{&Money{Units: 1, Nanos: 120000000, CurrencyCode: "USD"}, true, nil},
+		{&Money{Units: 1, Nanos: 125000000, CurrencyCode: "USD"}, false, nil},
+		{&Money{Units: -1, Nanos: -120000000, CurrencyCode: "USD"}, true, nil},
+		{&Money{Units: 1500, CurrencyCode: "JPY"}, true, nil},
+		{&Money{Units: 1500, Nanos: 1, CurrencyCode: "JPY"}, false, nil},
+		{&Money{Units: 1500, Nanos: 500000000, CurrencyCode: "JPY"}, false, nil},
+		{&Money{Units: 1, Nanos: 125000000, CurrencyCode: "KWD"}, true, nil},
+		{&Money{Units: 1, CurrencyCode: "XYZ"}, false, ErrUnknownCurrency},
+		{&Money{Units: 1, Nanos: -120000000, CurrencyCode: "USD"}, false, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := IsSettleable(v.input)
+		if err != v.err || res != v.expected {
+			t.Errorf("IsSettleable(%v): got %v, %v expected %v, %v", v.input, res, err, v.expected, v.err)
+		}
+	}
+}
+
+func TestRescaleMinorUnits(t *testing.T) {
+	cases := []struct {
+		amount         int64
+		fromExp, toExp int
+		expected       int64
+		err            error
+	}{
+		{1913, 2, 4, 191300, nil},
+		{-1913, 2, 4, -191300, nil},
+		{1500, 0, 2, 150000, nil},
+		{191300, 4, 2, 1913, nil},
+		{191350, 4, 2, 0, ErrPrecisionExceeded},
+		{1913, 2, 2, 1913, nil},
+		{math.MaxInt64, 2, 4, 0, ErrOverflow},
+		{1, -1, 2, 0, ErrInvalidPlaces},
+		{1, 2, 10, 0, ErrInvalidPlaces},
+		{1, 30, 0, 0, ErrInvalidPlaces},
+	}
+
+	for _, v := range cases {
+		res, err := RescaleMinorUnits(v.amount, v.fromExp, v.toExp)
+		if err != v.err || res != v.expected {
+			t.Errorf("RescaleMinorUnits(%d, %d, %d): got %d, %v expected %d, %v", v.amount, v.fromExp, v.toExp, res, err, v.expected, v.err)
+		}
+	}
+}
+
+func TestRescaleMinorUnitsRound(t *testing.T) {
+	cases := []struct {
+		amount   int64
+		mode     RoundingMode
+		expected int64
+	}{
+		{191350, HalfUp, 1914},
+		{191350, HalfEven, 1914},
+		{191250, HalfEven, 1912},
+		{191349, HalfUp, 1913},
+		{-191350, HalfUp, -1914},
+		{191301, TowardZero, 1913},
+		{191301, Ceiling, 1914},
+	}
+
+	for _, v := range cases {
+		res, err := RescaleMinorUnitsRound(v.amount, 4, 2, v.mode)
+		if err != nil || res != v.expected {
+			t.Errorf("RescaleMinorUnitsRound(%d, 4, 2, %v): got %d, %v expected %d", v.amount, v.mode, res, err, v.expected)
+		}
+	}
+	if _, err := RescaleMinorUnitsRound(1, 4, 2, RoundingMode(-1)); err != ErrInvalidRoundingMode {
+		t.Errorf("got error %v expected %v", err, ErrInvalidRoundingMode)
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FromMap builds a Money from a generically decoded JSON object holding the
+// "currencyCode", "units" and "nanos" keys. Units may be a string or a
+// number, nanos must be a number.
+func FromMap(m map[string]interface{}) (*Money, error) {
+	code, ok := m["currencyCode"]
+	if !ok {
+		return nil, fmt.Errorf("currencyCode: %w", ErrMissingField)
+	}
+	currencyCode, ok := code.(string)
+	if !ok {
+		return nil, fmt.Errorf("currencyCode: %w", ErrInvalidValue)
+	}
+
+	rawUnits, ok := m["units"]
+	if !ok {
+		return nil, fmt.Errorf("units: %w", ErrMissingField)
+	}
+	var units int64
+	switch u := rawUnits.(type) {
+	case string:
+		var err error
+		if units, err = strconv.ParseInt(u, 10, 64); err != nil {
+			return nil, fmt.Errorf("units: %w", ErrInvalidValue)
+		}
+	case float64:
+		if u != math.Trunc(u) || u < math.MinInt64 || u >= math.MaxInt64 {
+			return nil, fmt.Errorf("units: %w", ErrInvalidValue)
+		}
+		units = int64(u)
+	default:
+		return nil, fmt.Errorf("units: %w", ErrInvalidValue)
+	}
+
+	rawNanos, ok := m["nanos"]
+	if !ok {
+		return nil, fmt.Errorf("nanos: %w", ErrMissingField)
+	}
+	nanos, ok := rawNanos.(float64)
+	if !ok || nanos != math.Trunc(nanos) || nanos < nanosMin || nanos > nanosMax {
+		return nil, fmt.Errorf("nanos: %w", ErrInvalidValue)
+	}
+
+	res := &Money{
+		Units:        units,
+		Nanos:        int32(nanos),
+		CurrencyCode: currencyCode,
+	}
+	if !IsValid(res) {
+		return nil, ErrInvalidValue
+	}
+	return res, nil
+}
+
+// DecodeValidated builds a Money from the fields of an untrusted payload,
+// such as a gRPC message, checking everything the type requires: a
+// three-letter upper case currency code, nanos within range and units and
+// nanos of the same sign. The returned error names the offending field.
+func DecodeValidated(units int64, nanos int32, currency string) (*Money, error) {
+	if !isValidCurrencyCode(currency) {
+		return nil, fmt.Errorf("currencyCode %q: %w", currency, ErrInvalidCurrencyCode)
+	}
+	if !validNanos(nanos) {
+		return nil, fmt.Errorf("nanos %d out of range: %w", nanos, ErrInvalidValue)
+	}
+	m := &Money{Units: units, Nanos: nanos, CurrencyCode: currency}
+	if !signMatches(m) {
+		return nil, fmt.Errorf("units %d and nanos %d have different signs: %w", units, nanos, ErrInvalidValue)
+	}
+	return m, nil
+}
+
+// SanitizeSlice checks a batch of ingested values in one pass. Valid items
+// are kept as they are. Items whose units and nanos disagree in sign or whose
+// nanos are out of range are repaired to the canonical form of the same
+// amount, e.g. {Units: 1, Nanos: -1} becomes {Nanos: 999999999}, and kept as
+// new values. Nil items and items that can't be repaired because the amount
+// overflows are rejected, and their indices returned. The kept items must
+// share a currency, otherwise ErrMismatchingCurrency is returned.
+func SanitizeSlice(items []*Money) (valid []*Money, rejected []int, err error) {
+	code := ""
+	for i, m := range items {
+		if m == nil {
+			rejected = append(rejected, i)
+			continue
+		}
+		if !IsValid(m) {
+			if m, err = fromNanos(toNanos(m), m.CurrencyCode); err != nil {
+				rejected = append(rejected, i)
+				continue
+			}
+		}
+		if code, err = matchCurrency(code, m.CurrencyCode); err != nil {
+			return nil, nil, err
+		}
+		valid = append(valid, m)
+	}
+	return valid, rejected, nil
+}
+
+// FromProtoJSON decodes a Money from its protobuf JSON mapping as produced by
+// jsonpb: units is a string (a number is accepted too), nanos may be a number
+// or a string, and fields holding zero values may be omitted altogether. Both
+// "currencyCode" and the original "currency_code" field names are accepted.
+func FromProtoJSON(data []byte) (*Money, error) {
+	var raw struct {
+		CurrencyCode      string          `json:"currencyCode"`
+		CurrencyCodeProto string          `json:"currency_code"`
+		Units             json.RawMessage `json:"units"`
+		Nanos             json.RawMessage `json:"nanos"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	units, err := protoJSONInt(raw.Units, 64)
+	if err != nil {
+		return nil, fmt.Errorf("units: %w", ErrInvalidValue)
+	}
+	nanos, err := protoJSONInt(raw.Nanos, 32)
+	if err != nil {
+		return nil, fmt.Errorf("nanos: %w", ErrInvalidValue)
+	}
+	code := raw.CurrencyCode
+	if code == "" {
+		code = raw.CurrencyCodeProto
+	}
+
+	res := &Money{
+		Units:        units,
+		Nanos:        int32(nanos),
+		CurrencyCode: code,
+	}
+	if !IsValid(res) {
+		return nil, ErrInvalidValue
+	}
+	return res, nil
+}
+
+// protoJSONInt parses an integer field that the protobuf JSON mapping may
+// encode either as a number or as a string. A missing field is zero.
+func protoJSONInt(raw json.RawMessage, bitSize int) (int64, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+	s := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return 0, err
+		}
+	}
+	return strconv.ParseInt(s, 10, bitSize)
+}
+
+// MarshalText implements encoding.TextMarshaler. It produces the same text as
+// String, e.g. "USD 19.13". A nil Money marshals to empty text. It returns
+// ErrInvalidCurrencyCode for a code UnmarshalText wouldn't read back, one
+// that isn't empty or three upper case letters.
+func (x *Money) MarshalText() ([]byte, error) {
+	if x == nil {
+		return nil, nil
+	}
+	if !IsValid(x) {
+		return nil, ErrInvalidValue
+	}
+	if x.CurrencyCode != "" && !isValidCurrencyCode(x.CurrencyCode) {
+		return nil, ErrInvalidCurrencyCode
+	}
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the output of
+// MarshalText: a currency code and a decimal amount separated by a space, or
+// a bare decimal amount without a currency.
+func (x *Money) UnmarshalText(text []byte) error {
+	if x == nil {
+		return ErrNilMoney
+	}
+	m, err := parseText(string(text))
+	if err != nil {
+		return err
+	}
+	*x = *m
+	return nil
+}
+
+// moneyJSON has the fields of Money without its methods, so that it encodes
+// as a plain JSON object.
+type moneyJSON Money
+
+// MarshalJSON implements json.Marshaler. It keeps the JSON object form, e.g.
+// {"Units":19,"Nanos":130000000,"CurrencyCode":"USD"}, rather than the text
+// form of MarshalText, so that a *Money encodes the same as a Money.
+func (x *Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*moneyJSON)(x))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the object form written
+// by MarshalJSON.
+func (x *Money) UnmarshalJSON(data []byte) error {
+	if x == nil {
+		return ErrNilMoney
+	}
+	return json.Unmarshal(data, (*moneyJSON)(x))
+}
+
+// Scan implements fmt.Scanner so that a Money can be read with fmt.Sscan and
+// friends. It accepts the same forms as UnmarshalText: a currency code
+// followed by a decimal amount, or a bare decimal amount, with any amount of
+// white space between the two.
+func (x *Money) Scan(state fmt.ScanState, verb rune) error {
+	if verb != 'v' && verb != 's' {
+		return fmt.Errorf("money: unsupported scan verb %%%c", verb)
+	}
+	tok, err := state.Token(true, nil)
+	if err != nil {
+		return err
+	}
+	s := string(tok)
+	code := ""
+	if isValidCurrencyCode(s) {
+		code = s
+		if tok, err = state.Token(true, nil); err != nil {
+			return err
+		}
+		if s = string(tok); s == "" {
+			return io.ErrUnexpectedEOF
+		}
+	}
+	m, err := Parse(s, code)
+	if err != nil {
+		return err
+	}
+	*x = *m
+	return nil
+}
+
+// parseText parses the "CODE amount" form produced by String.
+func parseText(s string) (*Money, error) {
+	i := strings.IndexByte(s, ' ')
+	if i < 0 {
+		return Parse(s, "")
+	}
+	if !isValidCurrencyCode(s[:i]) {
+		return nil, ErrInvalidCurrencyCode
+	}
+	return Parse(s[i+1:], s[:i])
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The units and nanos are
+// written as varints followed by the currency code prefixed with its length.
+// A nil Money marshals to no data.
+func (x *Money) MarshalBinary() ([]byte, error) {
+	if x == nil {
+		return nil, nil
+	}
+	if !IsValid(x) {
+		return nil, ErrInvalidValue
+	}
+	buf := make([]byte, 3*binary.MaxVarintLen64+len(x.CurrencyCode))
+	n := binary.PutVarint(buf, x.Units)
+	n += binary.PutVarint(buf[n:], int64(x.Nanos))
+	n += binary.PutUvarint(buf[n:], uint64(len(x.CurrencyCode)))
+	n += copy(buf[n:], x.CurrencyCode)
+	return buf[:n], nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, decoding the output
+// of MarshalBinary. It returns io.ErrUnexpectedEOF if data is cut short.
+func (x *Money) UnmarshalBinary(data []byte) error {
+	if x == nil {
+		return ErrNilMoney
+	}
+	units, n := binary.Varint(data)
+	if n <= 0 {
+		return binaryError(n)
+	}
+	data = data[n:]
+	nanos, n := binary.Varint(data)
+	if n <= 0 {
+		return binaryError(n)
+	}
+	data = data[n:]
+	size, n := binary.Uvarint(data)
+	if n <= 0 {
+		return binaryError(n)
+	}
+	data = data[n:]
+	if uint64(len(data)) < size {
+		return io.ErrUnexpectedEOF
+	}
+	if uint64(len(data)) > size || nanos < nanosMin || nanos > nanosMax {
+		return ErrInvalidValue
+	}
+
+	m := Money{Units: units, Nanos: int32(nanos), CurrencyCode: string(data)}
+	if !IsValid(&m) {
+		return ErrInvalidValue
+	}
+	*x = m
+	return nil
+}
+
+// binaryError maps the result of a failed varint read to an error: zero
+// means the input ran out, a negative count that the value overflowed.
+func binaryError(n int) error {
+	if n == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return ErrInvalidValue
+}
+
+// AsRatio returns the amount of m as the fraction numerator/denominator in
+// lowest terms, e.g. 1/4 for 0.25. The denominator is positive and divides
+// 10^9. It returns ErrOverflow if the numerator doesn't fit in an int64.
+func AsRatio(m *Money) (numerator int64, denominator int64, err error) {
+	if !IsValid(m) {
+		return 0, 0, ErrInvalidValue
+	}
+	r := toRat(m)
+	if !r.Num().IsInt64() {
+		return 0, 0, ErrOverflow
+	}
+	return r.Num().Int64(), r.Denom().Int64(), nil
+}
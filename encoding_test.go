@@ -0,0 +1,403 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestFromMap(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected *Money
+		err      error
+	}{
+		{
+			`{"currencyCode": "USD", "units": "19", "nanos": 130000000}`,
+			&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, nil,
+		},
+		{
+			`{"currencyCode": "USD", "units": -2, "nanos": -500000000}`,
+			&Money{Units: -2, Nanos: -500000000, CurrencyCode: "USD"}, nil,
+		},
+		{`{"units": "19", "nanos": 0}`, nil, ErrMissingField},
+		{`{"currencyCode": "USD", "nanos": 0}`, nil, ErrMissingField},
+		{`{"currencyCode": "USD", "units": "19"}`, nil, ErrMissingField},
+		{`{"currencyCode": 840, "units": "19", "nanos": 0}`, nil, ErrInvalidValue},
+		{`{"currencyCode": "USD", "units": true, "nanos": 0}`, nil, ErrInvalidValue},
+		{`{"currencyCode": "USD", "units": "19.5", "nanos": 0}`, nil, ErrInvalidValue},
+		{`{"currencyCode": "USD", "units": 19.5, "nanos": 0}`, nil, ErrInvalidValue},
+		{`{"currencyCode": "USD", "units": "19", "nanos": "1"}`, nil, ErrInvalidValue},
+		{`{"currencyCode": "USD", "units": "19", "nanos": 1000000000}`, nil, ErrInvalidValue},
+		{`{"currencyCode": "USD", "units": "19", "nanos": -1}`, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(v.input), &m); err != nil {
+			t.Fatal(err)
+		}
+		res, err := FromMap(m)
+		if !errors.Is(err, v.err) || (err != nil) != (v.err != nil) {
+			t.Errorf("%s: got error %v expected %v", v.input, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%s: got %v expected %v", v.input, res, v.expected)
+		}
+	}
+}
+
+func TestFromProtoJSON(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected *Money
+		err      error
+	}{
+		{`{"currencyCode": "USD", "units": "19", "nanos": 130000000}`, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, nil},
+		{`{"currencyCode": "USD", "units": "19"}`, &Money{Units: 19, CurrencyCode: "USD"}, nil},
+		{`{"currencyCode": "USD", "nanos": 500000000}`, &Money{Nanos: 500000000, CurrencyCode: "USD"}, nil},
+		{`{"currency_code": "EUR", "units": 7, "nanos": "-0"}`, &Money{Units: 7, CurrencyCode: "EUR"}, nil},
+		{`{"currencyCode": "USD", "units": "-9223372036854775808", "nanos": -1}`, &Money{Units: math.MinInt64, Nanos: -1, CurrencyCode: "USD"}, nil},
+		{`{}`, &Money{}, nil},
+		{`{"currencyCode": "USD", "units": "19.5"}`, nil, ErrInvalidValue},
+		{`{"currencyCode": "USD", "units": true}`, nil, ErrInvalidValue},
+		{`{"currencyCode": "USD", "nanos": 3000000000}`, nil, ErrInvalidValue},
+		{`{"currencyCode": "USD", "units": "1", "nanos": -1}`, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := FromProtoJSON([]byte(v.input))
+		if !errors.Is(err, v.err) || (err != nil) != (v.err != nil) {
+			t.Errorf("%s: got error %v expected %v", v.input, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%s: got %v expected %v", v.input, res, v.expected)
+		}
+	}
+
+	if _, err := FromProtoJSON([]byte(`{"units":`)); err == nil {
+		t.Errorf("expected an error for malformed JSON")
+	}
+}
+
+func TestMarshalText(t *testing.T) {
+	cases := []*Money{
+		{Units: 19, Nanos: 130000000, CurrencyCode: "USD"},
+		{Units: -2, Nanos: -500000000, CurrencyCode: "EUR"},
+		{Nanos: 1, CurrencyCode: "USD"},
+		{Units: 285, CurrencyCode: "JPY"},
+		{Units: 3, Nanos: 250000000},
+	}
+
+	for _, v := range cases {
+		text, err := v.MarshalText()
+		if err != nil {
+			t.Errorf("%v: %v", v, err)
+			continue
+		}
+		var res Money
+		if err := res.UnmarshalText(text); err != nil {
+			t.Errorf("%q: %v", text, err)
+			continue
+		}
+		if res != *v {
+			t.Errorf("%q: got %+v expected %+v", text, res, *v)
+		}
+	}
+
+	text, err := (&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}).MarshalText()
+	if err != nil || string(text) != "USD 19.13" {
+		t.Errorf("got %q, %v expected %q", text, err, "USD 19.13")
+	}
+	if _, err := (&Money{Units: 1, Nanos: -1}).MarshalText(); err != ErrInvalidValue {
+		t.Errorf("invalid value: got error %v expected %v", err, ErrInvalidValue)
+	}
+	for _, code := range []string{"usdc", "usd", "US D"} {
+		if _, err := (&Money{Units: 1, CurrencyCode: code}).MarshalText(); err != ErrInvalidCurrencyCode {
+			t.Errorf("%q: got error %v expected %v", code, err, ErrInvalidCurrencyCode)
+		}
+	}
+}
+
+func TestMarshalTextNil(t *testing.T) {
+	var m *Money
+	text, err := m.MarshalText()
+	if err != nil || text != nil {
+		t.Errorf("got %q, %v expected empty text", text, err)
+	}
+	if err := m.UnmarshalText([]byte("USD 1")); err != ErrNilMoney {
+		t.Errorf("got error %v expected %v", err, ErrNilMoney)
+	}
+}
+
+func TestUnmarshalTextErrors(t *testing.T) {
+	cases := []struct {
+		input string
+		err   error
+	}{
+		{"usd 1.00", ErrInvalidCurrencyCode},
+		{" 1.00", ErrInvalidCurrencyCode},
+		{"USD", ErrInvalidDecimal},
+		{"USD 1,00", ErrInvalidDecimal},
+		{"", ErrInvalidDecimal},
+	}
+
+	for _, v := range cases {
+		var m Money
+		if err := m.UnmarshalText([]byte(v.input)); err != v.err {
+			t.Errorf("%q: got error %v expected %v", v.input, err, v.err)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	m := Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}
+	expected := `{"Units":19,"Nanos":130000000,"CurrencyCode":"USD"}`
+	for _, v := range []interface{}{m, &m} {
+		data, err := json.Marshal(v)
+		if err != nil || string(data) != expected {
+			t.Errorf("%T: got %s, %v expected %s", v, data, err, expected)
+		}
+	}
+
+	data, err := json.Marshal(map[string]*Money{"price": &m, "none": nil})
+	if expected := `{"none":null,"price":` + expected + `}`; err != nil || string(data) != expected {
+		t.Errorf("got %s, %v expected %s", data, err, expected)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var res struct {
+		Price *Money
+		Total Money
+	}
+	data := `{"Price":{"Units":19,"Nanos":130000000,"CurrencyCode":"USD"},"Total":{"Units":-2,"Nanos":-500000000}}`
+	if err := json.Unmarshal([]byte(data), &res); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}); res.Price == nil || *res.Price != expected {
+		t.Errorf("got %v expected %v", res.Price, expected)
+	}
+	if expected := (Money{Units: -2, Nanos: -500000000}); res.Total != expected {
+		t.Errorf("got %v expected %v", res.Total, expected)
+	}
+
+	var m *Money
+	if err := m.UnmarshalJSON([]byte(`{"Units":1}`)); err != ErrNilMoney {
+		t.Errorf("got error %v expected %v", err, ErrNilMoney)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	cases := []*Money{
+		{Units: 19, Nanos: 130000000, CurrencyCode: "USD"},
+		{Units: -2, Nanos: -500000000, CurrencyCode: "EUR"},
+		{Units: math.MaxInt64, Nanos: nanosMax, CurrencyCode: "USD"},
+		{Units: math.MinInt64, Nanos: nanosMin, CurrencyCode: "USD"},
+		{Nanos: -1},
+		{},
+	}
+
+	for _, v := range cases {
+		data, err := v.MarshalBinary()
+		if err != nil {
+			t.Errorf("%v: %v", v, err)
+			continue
+		}
+		var res Money
+		if err := res.UnmarshalBinary(data); err != nil {
+			t.Errorf("%v: %v", v, err)
+			continue
+		}
+		if res != *v {
+			t.Errorf("got %+v expected %+v", res, *v)
+		}
+	}
+
+	if _, err := (&Money{Units: 1, Nanos: -1}).MarshalBinary(); err != ErrInvalidValue {
+		t.Errorf("invalid value: got error %v expected %v", err, ErrInvalidValue)
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	data, err := (&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(data); i++ {
+		var m Money
+		if err := m.UnmarshalBinary(data[:i]); err != io.ErrUnexpectedEOF {
+			t.Errorf("truncated to %d bytes: got error %v expected %v", i, err, io.ErrUnexpectedEOF)
+		}
+	}
+
+	var m Money
+	if err := m.UnmarshalBinary(append(data, 0)); err != ErrInvalidValue {
+		t.Errorf("trailing data: got error %v expected %v", err, ErrInvalidValue)
+	}
+	var nilMoney *Money
+	if err := nilMoney.UnmarshalBinary(data); err != ErrNilMoney {
+		t.Errorf("nil receiver: got error %v expected %v", err, ErrNilMoney)
+	}
+}
+
+func TestAsRatio(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		num, den int64
+		err      error
+	}{
+		{&Money{Nanos: 250000000}, 1, 4, nil},
+		{&Money{Units: 19, Nanos: 130000000}, 1913, 100, nil},
+		{&Money{Units: -2, Nanos: -500000000}, -5, 2, nil},
+		{&Money{Units: 3}, 3, 1, nil},
+		{&Money{Nanos: 1}, 1, 1000000000, nil},
+		{&Money{}, 0, 1, nil},
+		{&Money{Units: math.MaxInt64}, math.MaxInt64, 1, nil},
+		{&Money{Units: math.MaxInt64, Nanos: 500000000}, 0, 0, ErrOverflow},
+		{&Money{Units: 1, Nanos: -1}, 0, 0, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		num, den, err := AsRatio(v.input)
+		if err != v.err || num != v.num || den != v.den {
+			t.Errorf("AsRatio(%v): got %d/%d, %v expected %d/%d, %v", v.input, num, den, err, v.num, v.den, v.err)
+		}
+	}
+}
+
+func TestDecodeValidated(t *testing.T) {
+	cases := []struct {
+		units    int64
+		nanos    int32
+		currency string
+		err      error
+		message  string
+	}{
+		{19, 130000000, "USD", nil, ""},
+		{-2, -500000000, "EUR", nil, ""},
+		{0, -1, "USD", nil, ""},
+		{1, 0, "", ErrInvalidCurrencyCode, `currencyCode "": currency code is missing or malformed`},
+		{1, 0, "usd", ErrInvalidCurrencyCode, `currencyCode "usd": currency code is missing or malformed`},
+		{1, 1000000000, "USD", ErrInvalidValue, "nanos 1000000000 out of range: one of the specified money values is invalid"},
+		{1, -1, "USD", ErrInvalidValue, "units 1 and nanos -1 have different signs: one of the specified money values is invalid"},
+	}
+
+	for _, v := range cases {
+		res, err := DecodeValidated(v.units, v.nanos, v.currency)
+		if v.err == nil {
+			expected := Money{Units: v.units, Nanos: v.nanos, CurrencyCode: v.currency}
+			if err != nil || *res != expected {
+				t.Errorf("got %v, %v expected %v", res, err, expected)
+			}
+			continue
+		}
+		if !errors.Is(err, v.err) || err.Error() != v.message {
+			t.Errorf("got error %v expected %q", err, v.message)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected Money
+	}{
+		{"USD 19.13", Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}},
+		{"  EUR\t-2.5", Money{Units: -2, Nanos: -500000000, CurrencyCode: "EUR"}},
+		{"7", Money{Units: 7}},
+	}
+
+	for _, v := range cases {
+		var m Money
+		if n, err := fmt.Sscan(v.input, &m); err != nil || n != 1 {
+			t.Errorf("%q: got %d, %v", v.input, n, err)
+			continue
+		}
+		if m != v.expected {
+			t.Errorf("%q: got %+v expected %+v", v.input, m, v.expected)
+		}
+	}
+
+	var price, tax Money
+	var qty int
+	if _, err := fmt.Sscan("USD 19.13 3 USD 1.5", &price, &qty, &tax); err != nil {
+		t.Fatal(err)
+	}
+	if price != (Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}) || qty != 3 || tax != (Money{Units: 1, Nanos: 500000000, CurrencyCode: "USD"}) {
+		t.Errorf("got %v, %d, %v", &price, qty, &tax)
+	}
+}
+
+func TestScanErrors(t *testing.T) {
+	cases := []struct {
+		input string
+		err   error
+	}{
+		{"USD", io.ErrUnexpectedEOF},
+		{"USD abc", ErrInvalidDecimal},
+		{"usd 1.00", ErrInvalidDecimal},
+	}
+
+	for _, v := range cases {
+		var m Money
+		if _, err := fmt.Sscan(v.input, &m); !errors.Is(err, v.err) {
+			t.Errorf("%q: got error %v expected %v", v.input, err, v.err)
+		}
+	}
+
+	var m Money
+	if _, err := fmt.Sscanf("1.00", "%d", &m); err == nil {
+		t.Errorf("%%d: expected an error")
+	}
+}
+
+func TestSanitizeSlice(t *testing.T) {
+	ok := &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}
+	items := []*Money{
+		ok,
+		nil,
+		{Units: 1, Nanos: -1, CurrencyCode: "USD"},
+		{Units: 2, Nanos: 1500000000, CurrencyCode: "USD"},
+		{Units: math.MaxInt64, Nanos: 1000000000, CurrencyCode: "USD"},
+		{Nanos: -1},
+	}
+	expected := []Money{
+		*ok,
+		{Nanos: 999999999, CurrencyCode: "USD"},
+		{Units: 3, Nanos: 500000000, CurrencyCode: "USD"},
+		{Nanos: -1},
+	}
+
+	valid, rejected, err := SanitizeSlice(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valid) != len(expected) {
+		t.Fatalf("got %v expected %v", valid, expected)
+	}
+	for i := range valid {
+		if *valid[i] != expected[i] {
+			t.Errorf("item %d: got %+v expected %+v", i, *valid[i], expected[i])
+		}
+	}
+	if valid[0] != ok {
+		t.Errorf("valid item was copied")
+	}
+	if items[2].Units != 1 || items[2].Nanos != -1 {
+		t.Errorf("input was modified: %+v", *items[2])
+	}
+	if len(rejected) != 2 || rejected[0] != 1 || rejected[1] != 4 {
+		t.Errorf("got rejected %v expected [1 4]", rejected)
+	}
+
+	mixed := append(items, &Money{Units: 1, CurrencyCode: "EUR"})
+	if _, _, err := SanitizeSlice(mixed); err != ErrMismatchingCurrency {
+		t.Errorf("got error %v expected %v", err, ErrMismatchingCurrency)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// validRate reports whether rate is a finite, non-negative number.
+func validRate(rate float64) bool {
+	return rate >= 0 && !math.IsInf(rate, 0) && !math.IsNaN(rate)
+}
+
+// SimpleInterest returns the interest earned by principal over periods at
+// ratePerPeriod, i.e. principal * ratePerPeriod * periods, rounded to the
+// nearest nano.
+func SimpleInterest(principal *Money, ratePerPeriod float64, periods int) (*Money, error) {
+	if !validRate(ratePerPeriod) {
+		return nil, ErrInvalidRate
+	}
+	if periods < 0 {
+		return nil, ErrInvalidPeriods
+	}
+	if !IsValid(principal) {
+		return nil, ErrInvalidValue
+	}
+
+	interest := toRat(principal)
+	interest.Mul(interest, ratFromFloat(ratePerPeriod))
+	interest.Mul(interest, new(big.Rat).SetInt64(int64(periods)))
+	return fromRat(interest, principal.GetCurrencyCode(), HalfUp)
+}
+
+// Compound returns principal grown over periods at ratePerPeriod, i.e.
+// principal * (1 + ratePerPeriod)^periods. The growth factor is computed
+// exactly and only the final result is rounded to the nearest nano, so no
+// error accumulates over many periods.
+func Compound(principal *Money, ratePerPeriod float64, periods int) (*Money, error) {
+	if !validRate(ratePerPeriod) {
+		return nil, ErrInvalidRate
+	}
+	if periods < 0 {
+		return nil, ErrInvalidPeriods
+	}
+	if !IsValid(principal) {
+		return nil, ErrInvalidValue
+	}
+
+	factor := new(big.Rat).Add(big.NewRat(1, 1), ratFromFloat(ratePerPeriod))
+	grown := toRat(principal)
+	grown.Mul(grown, ratPow(factor, periods))
+	return fromRat(grown, principal.GetCurrencyCode(), HalfUp)
+}
+
+// ratPow returns x raised to the non-negative power n.
+func ratPow(x *big.Rat, n int) *big.Rat {
+	result := big.NewRat(1, 1)
+	base := new(big.Rat).Set(x)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result.Mul(result, base)
+		}
+		base.Mul(base, base)
+	}
+	return result
+}
+
+// AmortRow is one period of an amortization schedule.
+type AmortRow struct {
+	Period    int
+	Payment   *Money
+	Interest  *Money
+	Principal *Money
+	Balance   *Money
+}
+
+// AmortizationSchedule splits the repayment of principal over periods equal
+// payments at ratePerPeriod. Interest and payments are rounded to the nearest
+// nano; the last payment absorbs the accumulated rounding so that the final
+// balance is exactly zero. No payment takes more principal than is still
+// owed, so a tiny principal that is paid off early leaves zero payments in
+// the remaining periods.
+func AmortizationSchedule(principal *Money, ratePerPeriod float64, periods int) ([]AmortRow, error) {
+	if !validRate(ratePerPeriod) {
+		return nil, ErrInvalidRate
+	}
+	if periods <= 0 {
+		return nil, ErrInvalidPeriods
+	}
+	if principal == nil || !IsValid(principal) || toNanos(principal).Sign() < 0 {
+		return nil, ErrInvalidValue
+	}
+
+	code := principal.GetCurrencyCode()
+	rate := ratFromFloat(ratePerPeriod)
+	balance := toNanos(principal)
+
+	// payment = P * r / (1 - (1+r)^-n), or P / n without interest.
+	var payment *big.Int
+	if rate.Sign() == 0 {
+		payment = roundQuo(balance, big.NewInt(int64(periods)), HalfUp)
+	} else {
+		growth := ratPow(new(big.Rat).Add(big.NewRat(1, 1), rate), periods)
+		p := new(big.Rat).SetInt(balance)
+		p.Mul(p, rate).Mul(p, growth)
+		p.Quo(p, growth.Sub(growth, big.NewRat(1, 1)))
+		payment = roundQuo(p.Num(), p.Denom(), HalfUp)
+	}
+
+	rows := make([]AmortRow, periods)
+	for i := range rows {
+		interestRat := new(big.Rat).SetInt(balance)
+		interestRat.Mul(interestRat, rate)
+		interest := roundQuo(interestRat.Num(), interestRat.Denom(), HalfUp)
+
+		paid := new(big.Int).Sub(payment, interest)
+		if i == periods-1 || paid.Cmp(balance) > 0 {
+			paid.Set(balance)
+		}
+		balance = new(big.Int).Sub(balance, paid)
+
+		row := AmortRow{Period: i + 1}
+		var err error
+		if row.Payment, err = fromNanos(new(big.Int).Add(interest, paid), code); err != nil {
+			return nil, err
+		}
+		if row.Interest, err = fromNanos(interest, code); err != nil {
+			return nil, err
+		}
+		if row.Principal, err = fromNanos(paid, code); err != nil {
+			return nil, err
+		}
+		if row.Balance, err = fromNanos(balance, code); err != nil {
+			return nil, err
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// EffectiveRate returns the rate that turns base into result, i.e.
+// result / base, as the float64 nearest to the exact quotient. It is the
+// inverse of Mul and can be used to audit which rate was applied.
+func EffectiveRate(base, result *Money) (float64, error) {
+	if !IsValid(base) || !IsValid(result) {
+		return 0, ErrInvalidValue
+	}
+	if _, err := matchCurrency(base.GetCurrencyCode(), result.GetCurrencyCode()); err != nil {
+		return 0, err
+	}
+	b := toNanos(base)
+	if b.Sign() == 0 {
+		return 0, ErrDivisionByZero
+	}
+	rate, _ := new(big.Rat).SetFrac(toNanos(result), b).Float64()
+	return rate, nil
+}
+
+// Prorate returns the share of full for usedDays out of totalDays, i.e.
+// full * usedDays / totalDays, computed exactly and rounded to the nearest
+// nano with halves away from zero.
+func Prorate(full *Money, usedDays, totalDays int) (*Money, error) {
+	if totalDays <= 0 || usedDays < 0 {
+		return nil, ErrInvalidPeriods
+	}
+	if full == nil || !IsValid(full) {
+		return nil, ErrInvalidValue
+	}
+	r := toRat(full)
+	r.Mul(r, big.NewRat(int64(usedDays), int64(totalDays)))
+	return fromRat(r, full.CurrencyCode, HalfUp)
+}
+
+// SolveRate returns the rate r for which Mul(input, r) comes closest to
+// expected. Among the rates near expected / input it prefers the one with
+// the fewest significant digits, so that a rate of 1.2 is recovered as 1.2
+// rather than its nearest binary neighbour. It returns ErrInvalidRate if
+// input and expected have opposite signs, as Mul only takes non-negative
+// rates.
+func SolveRate(input, expected *Money) (float64, error) {
+	rate, err := EffectiveRate(input, expected)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 {
+		return 0, ErrInvalidRate
+	}
+
+	if rate == 0 {
+		return 0, nil
+	}
+
+	// Mul cuts its product to whole nanos, so a rate just above the exact
+	// quotient may be needed: try both neighbours at each precision.
+	want := toNanos(expected)
+	magnitude := math.Floor(math.Log10(rate))
+	var best float64
+	var bestDist *big.Int
+	for digits := 1; digits <= 17; digits++ {
+		step := math.Pow(10, magnitude-float64(digits)+1)
+		nearest := roundSignificant(rate, digits)
+		for _, candidate := range []float64{nearest, roundSignificant(nearest+step, digits)} {
+			got, err := Mul(input, candidate)
+			if err != nil {
+				continue
+			}
+			dist := toNanos(got)
+			dist.Abs(dist.Sub(dist, want))
+			if bestDist == nil || dist.Cmp(bestDist) < 0 {
+				best, bestDist = candidate, dist
+			}
+		}
+		if bestDist != nil && bestDist.Sign() == 0 {
+			break
+		}
+	}
+	if bestDist == nil {
+		return 0, ErrOverflow
+	}
+	return best, nil
+}
+
+// roundSignificant rounds f to the given number of significant digits.
+func roundSignificant(f float64, digits int) float64 {
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'g', digits, 64), 64)
+	return r
+}
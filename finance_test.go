@@ -0,0 +1,255 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestSimpleInterest(t *testing.T) {
+	cases := []struct {
+		principal *Money
+		rate      float64
+		periods   int
+		expected  *Money
+		err       error
+	}{
+		{
+			&Money{Units: 1000, CurrencyCode: "USD"}, 0.05, 3,
+			&Money{Units: 150, CurrencyCode: "USD"}, nil,
+		},
+		{
+			&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, 0.015, 12,
+			&Money{Units: 3, Nanos: 443400000, CurrencyCode: "USD"}, nil,
+		},
+		{
+			&Money{Units: 0, Nanos: 1, CurrencyCode: "USD"}, 0.5, 1,
+			&Money{Units: 0, Nanos: 1, CurrencyCode: "USD"}, nil,
+		},
+		{
+			&Money{Units: 1000, CurrencyCode: "USD"}, 0.05, 0,
+			&Money{Units: 0, CurrencyCode: "USD"}, nil,
+		},
+		{&Money{Units: 1000}, -0.05, 1, nil, ErrInvalidRate},
+		{&Money{Units: 1000}, math.NaN(), 1, nil, ErrInvalidRate},
+		{&Money{Units: 1000}, 0.05, -1, nil, ErrInvalidPeriods},
+		{&Money{Units: 1, Nanos: -1}, 0.05, 1, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := SimpleInterest(v.principal, v.rate, v.periods)
+		if err != v.err {
+			t.Errorf("%v * %v * %d: got error %v expected %v", v.principal, v.rate, v.periods, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v * %v * %d: got %v expected %v", v.principal, v.rate, v.periods, res, v.expected)
+		}
+	}
+}
+
+func TestCompound(t *testing.T) {
+	principal := &Money{Units: 1000, Nanos: 0, CurrencyCode: "USD"}
+	res, err := Compound(principal, 0.01, 120)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reference: 1000 * 1.01^120 computed with 512 bits of precision.
+	const prec = 512
+	rate, _ := new(big.Float).SetPrec(prec).SetString("1.01")
+	ref := new(big.Float).SetPrec(prec).SetInt64(1000)
+	for i := 0; i < 120; i++ {
+		ref.Mul(ref, rate)
+	}
+	ref.Mul(ref, new(big.Float).SetPrec(prec).SetInt64(nanosMod))
+	ref.Add(ref, big.NewFloat(0.5).SetPrec(prec))
+	refNanos, _ := ref.Int(nil)
+
+	if got := toNanos(res); got.Cmp(refNanos) != 0 {
+		t.Errorf("got %v nanos expected %v", got, refNanos)
+	}
+	if res.CurrencyCode != "USD" {
+		t.Errorf("got currency %q expected USD", res.CurrencyCode)
+	}
+
+	res, err = Compound(principal, 0.05, 0)
+	if err != nil || *res != *principal {
+		t.Errorf("zero periods: got %v, %v expected %v", res, err, principal)
+	}
+	if _, err := Compound(principal, math.Inf(1), 1); err != ErrInvalidRate {
+		t.Errorf("got %v expected ErrInvalidRate", err)
+	}
+}
+
+func TestAmortizationSchedule(t *testing.T) {
+	principal := &Money{Units: 1000, CurrencyCode: "USD"}
+	rows, err := AmortizationSchedule(principal, 0.01, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 12 {
+		t.Fatalf("got %d rows expected 12", len(rows))
+	}
+
+	// 1000 * 0.01 / (1 - 1.01^-12) = 88.84878867834...
+	expectedPayment := &Money{Units: 88, Nanos: 848788678, CurrencyCode: "USD"}
+	AssertEqual(t, rows[0].Payment, expectedPayment)
+	AssertEqual(t, rows[0].Interest, &Money{Units: 10, CurrencyCode: "USD"})
+
+	paid := new(big.Int)
+	for i, row := range rows {
+		if row.Period != i+1 {
+			t.Errorf("row %d has period %d", i, row.Period)
+		}
+		sum := new(big.Int).Add(toNanos(row.Interest), toNanos(row.Principal))
+		if sum.Cmp(toNanos(row.Payment)) != 0 {
+			t.Errorf("period %d: interest %v + principal %v != payment %v", row.Period, row.Interest, row.Principal, row.Payment)
+		}
+		paid.Add(paid, toNanos(row.Principal))
+	}
+	if last := rows[len(rows)-1].Balance; !IsZero(last) {
+		t.Errorf("got final balance %v expected zero", last)
+	}
+	if paid.Cmp(toNanos(principal)) != 0 {
+		t.Errorf("principal paid %v nanos expected %v", paid, toNanos(principal))
+	}
+
+	rows, err = AmortizationSchedule(&Money{Units: 100, CurrencyCode: "USD"}, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equals(rows[0].Payment, &Money{Units: 33, Nanos: 333333333, CurrencyCode: "USD"}) ||
+		!Equals(rows[2].Payment, &Money{Units: 33, Nanos: 333333334, CurrencyCode: "USD"}) || !IsZero(rows[2].Balance) {
+		t.Errorf("unexpected interest free schedule %+v", rows)
+	}
+
+	for _, rate := range []float64{0, 0.01} {
+		tiny := &Money{Nanos: 10, CurrencyCode: "USD"}
+		rows, err = AmortizationSchedule(tiny, rate, 19)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paid.SetInt64(0)
+		for _, row := range rows {
+			if IsNegative(row.Balance) || IsNegative(row.Principal) || IsNegative(row.Payment) {
+				t.Errorf("rate %v, period %d: got negative row %+v", rate, row.Period, row)
+			}
+			paid.Add(paid, toNanos(row.Principal))
+		}
+		if paid.Cmp(toNanos(tiny)) != 0 || !IsZero(rows[len(rows)-1].Balance) {
+			t.Errorf("rate %v: principal paid %v nanos, final balance %v expected 10 and zero", rate, paid, rows[len(rows)-1].Balance)
+		}
+	}
+
+	if _, err := AmortizationSchedule(&Money{Units: -1}, 0.01, 12); err != ErrInvalidValue {
+		t.Errorf("got %v expected ErrInvalidValue", err)
+	}
+	if _, err := AmortizationSchedule(principal, -0.01, 12); err != ErrInvalidRate {
+		t.Errorf("got %v expected ErrInvalidRate", err)
+	}
+	if _, err := AmortizationSchedule(principal, 0.01, -1); err != ErrInvalidPeriods {
+		t.Errorf("got %v expected ErrInvalidPeriods", err)
+	}
+}
+
+func TestEffectiveRate(t *testing.T) {
+	cases := []struct {
+		base, result *Money
+		expected     float64
+		err          error
+	}{
+		{&Money{Units: 19, CurrencyCode: "USD"}, &Money{Units: 22, Nanos: 800000000, CurrencyCode: "USD"}, 1.2, nil},
+		{&Money{Nanos: 700000000, CurrencyCode: "USD"}, &Money{Units: 10, Nanos: 570000000, CurrencyCode: "USD"}, 15.1, nil},
+		{&Money{Units: 3, CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "USD"}, 1.0 / 3, nil},
+		{&Money{Units: -4, CurrencyCode: "USD"}, &Money{Units: 2, CurrencyCode: "USD"}, -0.5, nil},
+		{&Money{CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "USD"}, 0, ErrDivisionByZero},
+		{&Money{Units: 1, CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "EUR"}, 0, ErrMismatchingCurrency},
+		{&Money{Units: 1, Nanos: -1}, &Money{Units: 1}, 0, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := EffectiveRate(v.base, v.result)
+		if err != v.err || res != v.expected {
+			t.Errorf("EffectiveRate(%v, %v): got %v, %v expected %v, %v", v.base, v.result, res, err, v.expected, v.err)
+		}
+	}
+}
+
+func TestProrate(t *testing.T) {
+	cases := []struct {
+		full        *Money
+		used, total int
+		expected    *Money
+		err         error
+	}{
+		{&Money{Units: 30, CurrencyCode: "USD"}, 10, 30, &Money{Units: 10, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 9, Nanos: 990000000, CurrencyCode: "USD"}, 10, 30, &Money{Units: 3, Nanos: 330000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, 10, 30, &Money{Units: 3, Nanos: 333333333, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 20, CurrencyCode: "USD"}, 10, 30, &Money{Units: 6, Nanos: 666666667, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 20, CurrencyCode: "USD"}, 0, 30, &Money{CurrencyCode: "USD"}, nil},
+		{&Money{Units: 20, CurrencyCode: "USD"}, 31, 31, &Money{Units: 20, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 20, CurrencyCode: "USD"}, 1, 0, nil, ErrInvalidPeriods},
+		{&Money{Units: 20, CurrencyCode: "USD"}, -1, 30, nil, ErrInvalidPeriods},
+		{&Money{Units: 20, Nanos: -1}, 1, 30, nil, ErrInvalidValue},
+		{nil, 1, 30, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := Prorate(v.full, v.used, v.total)
+		if err != v.err {
+			t.Errorf("Prorate(%v, %d, %d): got error %v expected %v", v.full, v.used, v.total, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("Prorate(%v, %d, %d): got %v expected %v", v.full, v.used, v.total, res, v.expected)
+		}
+	}
+}
+
+func TestSolveRate(t *testing.T) {
+	cases := []struct {
+		input, expected *Money
+		rate            float64
+	}{
+		{&Money{Units: 19}, &Money{Units: 22, Nanos: 800000000}, 1.2},
+		{&Money{Nanos: 700000000}, &Money{Units: 10, Nanos: 570000000}, 15.1},
+		{&Money{Units: 1000, CurrencyCode: "USD"}, &Money{Units: 1101, CurrencyCode: "USD"}, 1.101},
+		{&Money{Units: 5}, &Money{Nanos: 5500000}, 0.0011},
+		{&Money{Units: -4}, &Money{Units: -5}, 1.25},
+		{&Money{Units: 3}, &Money{Units: 1}, 0.3333333334},
+		{&Money{Units: 3}, &Money{}, 0},
+	}
+
+	for _, v := range cases {
+		res, err := SolveRate(v.input, v.expected)
+		if err != nil {
+			t.Errorf("SolveRate(%v, %v): %v", v.input, v.expected, err)
+			continue
+		}
+		if math.Abs(res-v.rate) > 1e-12 {
+			t.Errorf("SolveRate(%v, %v): got %v expected %v", v.input, v.expected, res, v.rate)
+		}
+		if got, err := Mul(v.input, res); err != nil || !Equals(got, v.expected) {
+			t.Errorf("Mul(%v, %v) got %v, %v expected %v", v.input, res, got, err, v.expected)
+		}
+	}
+}
+
+func TestSolveRateErrors(t *testing.T) {
+	cases := []struct {
+		input, expected *Money
+		err             error
+	}{
+		{&Money{CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "USD"}, ErrDivisionByZero},
+		{&Money{Units: 1, CurrencyCode: "USD"}, &Money{Units: 1, CurrencyCode: "EUR"}, ErrMismatchingCurrency},
+		{&Money{Units: 1}, &Money{Units: -1}, ErrInvalidRate},
+		{&Money{Units: 1, Nanos: -1}, &Money{Units: 1}, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		if _, err := SolveRate(v.input, v.expected); err != v.err {
+			t.Errorf("SolveRate(%v, %v): got error %v expected %v", v.input, v.expected, err, v.err)
+		}
+	}
+}
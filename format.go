@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// String returns m as its currency code followed by the decimal amount with
+// trailing zeros trimmed, e.g. "USD 19.13". The code is omitted when empty.
+func (x *Money) String() string {
+	if x == nil {
+		return "<nil>"
+	}
+	if x.CurrencyCode == "" {
+		return decimalString(x)
+	}
+	return x.CurrencyCode + " " + decimalString(x)
+}
+
+// AccountingColumns renders x as a double-entry pair: the magnitude of a
+// positive amount goes in the debit column and that of a negative amount in
+// the credit column, with the other column left blank. A zero or nil amount
+// leaves both columns blank. The currency code is not included.
+func (x *Money) AccountingColumns() (debit string, credit string) {
+	n := toNanos(x)
+	switch n.Sign() {
+	case 1:
+		return decimalString(x), ""
+	case -1:
+		return "", strings.TrimPrefix(decimalString(x), "-")
+	}
+	return "", ""
+}
+
+// decimalString formats the amount of m as a decimal with trailing zeros
+// trimmed, e.g. "-1.5" or "285".
+func decimalString(m *Money) string {
+	n := toNanos(m)
+	sign := ""
+	if n.Sign() < 0 {
+		sign = "-"
+		n.Neg(n)
+	}
+	units, nanos := n.QuoRem(n, nanosPerUnit, new(big.Int))
+
+	frac := strings.TrimRight(fmt.Sprintf("%09d", nanos.Int64()), "0")
+	if frac == "" {
+		return sign + units.String()
+	}
+	return sign + units.String() + "." + frac
+}
+
+// FormatPlaces is like String but renders exactly places decimal places,
+// padding with zeros or truncating extra digits, e.g. "USD 19.1300" for 4
+// places. places is clamped to the range 0 to 9.
+func (x *Money) FormatPlaces(places int) string {
+	if x == nil {
+		return "<nil>"
+	}
+	if x.CurrencyCode == "" {
+		return fixedString(x, places)
+	}
+	return x.CurrencyCode + " " + fixedString(x, places)
+}
+
+// ToCsvField renders the amount of x for a CSV field with exactly places
+// decimal places, truncating extra digits like FormatPlaces, e.g. "19.13" or
+// "-0.500000000". The currency code is left out and a nil Money gives an
+// empty field. With 9 places the amount survives a round trip through Parse.
+func (x *Money) ToCsvField(places int) string {
+	if x == nil {
+		return ""
+	}
+	return fixedString(x, places)
+}
+
+// fixedString formats the amount of m with exactly places decimal places,
+// truncating towards zero. places is clamped to the range 0 to 9.
+func fixedString(m *Money, places int) string {
+	if places < 0 {
+		places = 0
+	} else if places > 9 {
+		places = 9
+	}
+
+	n := toNanos(m)
+	negative := n.Sign() < 0
+	units, nanos := n.QuoRem(n.Abs(n), nanosPerUnit, new(big.Int))
+
+	frac := fmt.Sprintf("%09d", nanos.Int64())[:places]
+	s := units.String()
+	if places > 0 {
+		s += "." + frac
+	}
+	// Don't render a minus sign for a value truncated to zero.
+	if negative && strings.Trim(s, "0.") != "" {
+		s = "-" + s
+	}
+	return s
+}
+
+// FormatColumn formats the amounts of items for display in a column: each is
+// written with as many decimal places as the most precise item needs and
+// padded on the left to a common width, so that the decimal points line up.
+// Currency codes are left out and nil items are rendered as blanks.
+func FormatColumn(items []*Money) []string {
+	places := 0
+	for _, m := range items {
+		if m == nil {
+			continue
+		}
+		s := decimalString(m)
+		if i := strings.IndexByte(s, '.'); i >= 0 && len(s)-i-1 > places {
+			places = len(s) - i - 1
+		}
+	}
+
+	res := make([]string, len(items))
+	width := 0
+	for i, m := range items {
+		if m != nil {
+			res[i] = fixedString(m, places)
+		}
+		if len(res[i]) > width {
+			width = len(res[i])
+		}
+	}
+	for i, s := range res {
+		res[i] = strings.Repeat(" ", width-len(s)) + s
+	}
+	return res
+}
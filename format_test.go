@@ -0,0 +1,132 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		expected string
+	}{
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, "USD 19.13"},
+		{&Money{Units: 285, Nanos: 0, CurrencyCode: "USD"}, "USD 285"},
+		{&Money{Units: 0, Nanos: 55000}, "0.000055"},
+		{&Money{Units: -1, Nanos: -500000000, CurrencyCode: "EUR"}, "EUR -1.5"},
+		{&Money{Units: 0, Nanos: -1}, "-0.000000001"},
+		{&Money{}, "0"},
+		{nil, "<nil>"},
+	}
+
+	for _, v := range cases {
+		if res := v.input.String(); res != v.expected {
+			t.Errorf("got:%q expected:%q", res, v.expected)
+		}
+	}
+}
+
+func TestFormatPlaces(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		places   int
+		expected string
+	}{
+		{&Money{Units: 19, Nanos: 130000000}, 4, "19.1300"},
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, 4, "USD 19.1300"},
+		{&Money{Units: 19, Nanos: 136789000, CurrencyCode: "USD"}, 2, "USD 19.13"},
+		{&Money{Units: 19, Nanos: 136789000, CurrencyCode: "USD"}, 0, "USD 19"},
+		{&Money{Units: 0, Nanos: 123456789}, 9, "0.123456789"},
+		{&Money{Units: 0, Nanos: 123456789}, 12, "0.123456789"},
+		{&Money{Units: 5}, -1, "5"},
+		{&Money{Units: -1, Nanos: -500000000}, 3, "-1.500"},
+		{&Money{Units: 0, Nanos: -1000000}, 2, "0.00"},
+		{&Money{Units: 0, Nanos: -10000000}, 2, "-0.01"},
+		{nil, 2, "<nil>"},
+	}
+
+	for _, v := range cases {
+		if res := v.input.FormatPlaces(v.places); res != v.expected {
+			t.Errorf("%v with %d places got:%q expected:%q", v.input, v.places, res, v.expected)
+		}
+	}
+}
+
+func TestFormatColumn(t *testing.T) {
+	items := []*Money{
+		{Units: 5},
+		{Units: 1234, Nanos: 500000000, CurrencyCode: "USD"},
+		{Units: -7, Nanos: -250000000},
+		nil,
+		{Nanos: 5000000},
+	}
+	expected := []string{
+		"   5.000",
+		"1234.500",
+		"  -7.250",
+		"        ",
+		"   0.005",
+	}
+
+	res := FormatColumn(items)
+	if len(res) != len(expected) {
+		t.Fatalf("got %d lines expected %d", len(res), len(expected))
+	}
+	for i := range res {
+		if res[i] != expected[i] {
+			t.Errorf("line %d got:%q expected:%q", i, res[i], expected[i])
+		}
+	}
+
+	if res := FormatColumn(nil); len(res) != 0 {
+		t.Errorf("got %q expected no lines", res)
+	}
+}
+
+func TestAccountingColumns(t *testing.T) {
+	cases := []struct {
+		input         *Money
+		debit, credit string
+	}{
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, "19.13", ""},
+		{&Money{Units: -2, Nanos: -500000000, CurrencyCode: "USD"}, "", "2.5"},
+		{&Money{Nanos: -1}, "", "0.000000001"},
+		{&Money{CurrencyCode: "USD"}, "", ""},
+		{nil, "", ""},
+	}
+
+	for _, v := range cases {
+		debit, credit := v.input.AccountingColumns()
+		if debit != v.debit || credit != v.credit {
+			t.Errorf("%v got:%q, %q expected:%q, %q", v.input, debit, credit, v.debit, v.credit)
+		}
+	}
+}
+
+func TestToCsvField(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		places   int
+		expected string
+	}{
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, 2, "19.13"},
+		{&Money{Units: 19, Nanos: 136789000, CurrencyCode: "USD"}, 2, "19.13"},
+		{&Money{Units: -1, Nanos: -500000000}, 2, "-1.50"},
+		{&Money{Nanos: -500000000}, 9, "-0.500000000"},
+		{&Money{Units: 19, Nanos: 130000000}, 9, "19.130000000"},
+		{&Money{Nanos: -1}, 9, "-0.000000001"},
+		{&Money{Nanos: -1}, 2, "0.00"},
+		{nil, 2, ""},
+	}
+
+	for _, v := range cases {
+		res := v.input.ToCsvField(v.places)
+		if res != v.expected {
+			t.Errorf("%v with %d places got:%q expected:%q", v.input, v.places, res, v.expected)
+		}
+		if v.places == 9 && v.input != nil {
+			if back, err := Parse(res, v.input.CurrencyCode); err != nil || !Equals(back, v.input) {
+				t.Errorf("%q parsed back to %v, %v expected %v", res, back, err, v.input)
+			}
+		}
+	}
+}
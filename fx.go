@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ConvertCross converts m into the currency to through a base currency.
+// rates holds, for each currency, how many units of it one unit of the base
+// is worth, e.g. {"USD": 1, "EUR": 0.9, "JPY": 150} with USD as the base. m
+// is divided by the rate of its own currency and multiplied by the rate of
+// to, and the result is rounded to the nearest nano with halves away from
+// zero. It returns ErrMissingRate if either currency has no rate.
+func ConvertCross(m *Money, to string, rates map[string]float64) (*Money, error) {
+	if m == nil || !IsValid(m) {
+		return nil, ErrInvalidValue
+	}
+	if !isValidCurrencyCode(m.CurrencyCode) || !isValidCurrencyCode(to) {
+		return nil, ErrInvalidCurrencyCode
+	}
+	fromRate, err := crossRate(rates, m.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := crossRate(rates, to)
+	if err != nil {
+		return nil, err
+	}
+
+	r := toRat(m)
+	r.Quo(r, fromRate)
+	r.Mul(r, toRate)
+	return fromRat(r, to, HalfUp)
+}
+
+// crossRate looks up the rate of code against the base currency.
+func crossRate(rates map[string]float64, code string) (*big.Rat, error) {
+	rate, ok := rates[code]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", code, ErrMissingRate)
+	}
+	if !validRate(rate) || rate == 0 {
+		return nil, fmt.Errorf("%s: %w", code, ErrInvalidRate)
+	}
+	return ratFromFloat(rate), nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestConvertCross(t *testing.T) {
+	rates := map[string]float64{"USD": 1, "EUR": 0.8, "JPY": 150, "GBP": 0.75}
+	cases := []struct {
+		input    *Money
+		to       string
+		expected *Money
+	}{
+		{&Money{Units: 100, CurrencyCode: "EUR"}, "JPY", &Money{Units: 18750, CurrencyCode: "JPY"}},
+		{&Money{Units: 100, CurrencyCode: "USD"}, "EUR", &Money{Units: 80, CurrencyCode: "EUR"}},
+		{&Money{Units: 1, CurrencyCode: "JPY"}, "USD", &Money{Nanos: 6666667, CurrencyCode: "USD"}},
+		{&Money{Units: -3, CurrencyCode: "GBP"}, "EUR", &Money{Units: -3, Nanos: -200000000, CurrencyCode: "EUR"}},
+		{&Money{Units: 7, CurrencyCode: "EUR"}, "EUR", &Money{Units: 7, CurrencyCode: "EUR"}},
+	}
+
+	for _, v := range cases {
+		res, err := ConvertCross(v.input, v.to, rates)
+		if err != nil || *res != *v.expected {
+			t.Errorf("%v to %s: got %v, %v expected %v", v.input, v.to, res, err, v.expected)
+		}
+	}
+}
+
+func TestConvertCrossErrors(t *testing.T) {
+	rates := map[string]float64{"USD": 1, "EUR": 0.8, "CHF": 0, "SEK": math.NaN()}
+	cases := []struct {
+		input *Money
+		to    string
+		err   error
+	}{
+		{&Money{Units: 1, CurrencyCode: "EUR"}, "JPY", ErrMissingRate},
+		{&Money{Units: 1, CurrencyCode: "JPY"}, "EUR", ErrMissingRate},
+		{&Money{Units: 1, CurrencyCode: "EUR"}, "CHF", ErrInvalidRate},
+		{&Money{Units: 1, CurrencyCode: "SEK"}, "EUR", ErrInvalidRate},
+		{&Money{Units: 1}, "EUR", ErrInvalidCurrencyCode},
+		{&Money{Units: 1, CurrencyCode: "EUR"}, "usd", ErrInvalidCurrencyCode},
+		{&Money{Units: 1, Nanos: -1, CurrencyCode: "EUR"}, "USD", ErrInvalidValue},
+		{nil, "USD", ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		if _, err := ConvertCross(v.input, v.to, rates); !errors.Is(err, v.err) {
+			t.Errorf("%v to %s: got error %v expected %v", v.input, v.to, err, v.err)
+		}
+	}
+}
@@ -1,11 +1,10 @@
 package main
 
 import (
-	"encoding/csv"
 	"errors"
 	"fmt"
-	"io"
 	"math"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
@@ -15,6 +14,10 @@ const (
 	nanosMin = -999999999
 	nanosMax = +999999999
 	nanosMod = 1000000000
+
+	// csvToleranceNanos absorbs legitimate one-nano rounding differences
+	// when verifying the CSV test files.
+	csvToleranceNanos = 1
 )
 
 type Money struct {
@@ -23,6 +26,53 @@ type Money struct {
 	CurrencyCode string
 }
 
+// MaxPrecision is the largest number of decimal places that New and Parse
+// accept, between 0 and 9. Values carrying more precision are rejected with
+// ErrPrecisionExceeded rather than rounded, so that a stray sub-cent amount
+// is noticed; round such values first, e.g. with ToCurrencyPrecision, if
+// that is what's wanted. Results of arithmetic are not checked. A setting
+// outside 0 to 9 is a misconfiguration that makes New and Parse fail with
+// ErrInvalidPlaces.
+var MaxPrecision = 9
+
+// New returns a Money with the given fields after checking that it is valid
+// and within MaxPrecision.
+func New(units int64, nanos int32, currencyCode string) (*Money, error) {
+	m := &Money{Units: units, Nanos: nanos, CurrencyCode: currencyCode}
+	if !IsValid(m) {
+		return nil, ErrInvalidValue
+	}
+	if err := checkPrecision(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MaxMoney returns the largest amount a Money can hold, in currency. It is a
+// convenient starting point when folding a slice to its minimum.
+func MaxMoney(currency string) *Money {
+	return &Money{Units: math.MaxInt64, Nanos: nanosMax, CurrencyCode: currency}
+}
+
+// MinMoney returns the smallest (most negative) amount a Money can hold, in
+// currency.
+func MinMoney(currency string) *Money {
+	return &Money{Units: math.MinInt64, Nanos: nanosMin, CurrencyCode: currency}
+}
+
+// checkPrecision returns ErrPrecisionExceeded if m uses more decimal places
+// than MaxPrecision allows, or ErrInvalidPlaces if MaxPrecision is out of
+// range.
+func checkPrecision(m *Money) error {
+	if MaxPrecision < 0 || MaxPrecision > 9 {
+		return ErrInvalidPlaces
+	}
+	if RequiredExponent(m) > MaxPrecision {
+		return ErrPrecisionExceeded
+	}
+	return nil
+}
+
 func (x *Money) GetCurrencyCode() string {
 	if x != nil {
 		return x.CurrencyCode
@@ -44,15 +94,115 @@ func (x *Money) GetNanos() int32 {
 	return 0
 }
 
+// MajorUnits returns the whole-unit part of x. Units and nanos are
+// normalized first, so MajorUnits and MinorUnitsFraction never have opposite
+// signs, even for a value such as {Units: 1, Nanos: -250000000}.
+func (x *Money) MajorUnits() int64 {
+	units, _ := x.normalizedParts()
+	return units
+}
+
+// MinorUnitsFraction returns the fractional part of x in nanos, with the same
+// sign as MajorUnits.
+func (x *Money) MinorUnitsFraction() int32 {
+	_, nanos := x.normalizedParts()
+	return nanos
+}
+
+// IntegerPart returns the whole units of x as a Money with no nanos, in the
+// currency of x. A nil x gives zero.
+func IntegerPart(x *Money) *Money {
+	units, _ := x.normalizedParts()
+	return &Money{Units: units, CurrencyCode: x.GetCurrencyCode()}
+}
+
+// FractionalPart returns what x has beyond its whole units, as a Money with
+// no units and nanos of the same sign as x, so that IntegerPart(x) plus
+// FractionalPart(x) is x. A nil x gives zero.
+func FractionalPart(x *Money) *Money {
+	_, nanos := x.normalizedParts()
+	return &Money{Nanos: nanos, CurrencyCode: x.GetCurrencyCode()}
+}
+
+// normalizedParts returns the units and nanos of x with matching signs and
+// nanos in range. Values that can't be normalized are returned as is.
+func (x *Money) normalizedParts() (int64, int32) {
+	m, err := fromNanos(toNanos(x), "")
+	if err != nil {
+		return x.GetUnits(), x.GetNanos()
+	}
+	return m.Units, m.Nanos
+}
+
 var (
-	// ErrInvalidMultiplierProvided is returned when a negative or zero multiplier is provided.
-	ErrInvalidMultiplierProvided = errors.New("multiplier provided is zero or negative which is invalid")
+	// ErrInvalidMultiplierProvided is returned when a negative, NaN or infinite multiplier is provided.
+	ErrInvalidMultiplierProvided = errors.New("multiplier provided is negative or not a finite number which is invalid")
 
 	// ErrInvalidValue is returned if the specified money amount is not valid.
 	ErrInvalidValue = errors.New("one of the specified money values is invalid")
 
 	// ErrMismatchingCurrency is returned if two values don't have the same currency code.
 	ErrMismatchingCurrency = errors.New("mismatching currency codes")
+
+	// ErrOverflow is returned when a result doesn't fit in the units of a Money.
+	ErrOverflow = errors.New("money value overflows")
+
+	// ErrInvalidRate is returned when a rate is negative, NaN or infinite.
+	ErrInvalidRate = errors.New("rate provided is negative or not a finite number")
+
+	// ErrInvalidPeriods is returned when a number of periods is out of range, e.g. negative.
+	ErrInvalidPeriods = errors.New("number of periods provided is invalid")
+
+	// ErrInvalidQuantity is returned when a zero or negative quantity is provided.
+	ErrInvalidQuantity = errors.New("quantity provided is zero or negative")
+
+	// ErrMissingField is returned when a required field is absent from decoded input.
+	ErrMissingField = errors.New("required field is missing")
+
+	// ErrUnknownCurrency is returned when a currency code is not a known ISO 4217 code.
+	ErrUnknownCurrency = errors.New("unknown currency code")
+
+	// ErrTotalBelowMinimum is returned when a total can't cover a single minimum share.
+	ErrTotalBelowMinimum = errors.New("total is smaller than the minimum share")
+
+	// ErrInvalidRoundingMode is returned when an undefined RoundingMode is provided.
+	ErrInvalidRoundingMode = errors.New("invalid rounding mode")
+
+	// ErrDivisionByZero is returned when dividing by a zero amount.
+	ErrDivisionByZero = errors.New("division by zero")
+
+	// ErrInvalidPercentage is returned when a percentage is outside the allowed range.
+	ErrInvalidPercentage = errors.New("percentage provided is out of range")
+
+	// ErrInvalidCurrencyCode is returned when a currency code is missing or isn't three upper case letters.
+	ErrInvalidCurrencyCode = errors.New("currency code is missing or malformed")
+
+	// ErrZeroTotal is returned when values to allocate against add up to zero.
+	ErrZeroTotal = errors.New("values add up to zero")
+
+	// ErrInvalidDecimal is returned when a string can't be parsed as a decimal amount.
+	ErrInvalidDecimal = errors.New("invalid decimal amount")
+
+	// ErrEmptySlice is returned when an operation needs at least one value and none is provided.
+	ErrEmptySlice = errors.New("no values provided")
+
+	// ErrNilMoney is returned when decoding into a nil Money.
+	ErrNilMoney = errors.New("decoding into a nil money value")
+
+	// ErrPrecisionExceeded is returned when a value has more decimal places than MaxPrecision allows.
+	ErrPrecisionExceeded = errors.New("value has more decimal places than allowed")
+
+	// ErrInvalidPlaces is returned when a number of decimal places is outside 0 to 9.
+	ErrInvalidPlaces = errors.New("number of decimal places is out of range")
+
+	// ErrInvalidRange is returned when a range is malformed or its low end is above its high end.
+	ErrInvalidRange = errors.New("invalid range")
+
+	// ErrMissingRate is returned when no exchange rate is known for a currency.
+	ErrMissingRate = errors.New("missing exchange rate")
+
+	// ErrCapsExceeded is returned when a total is larger than the caps it has to fit under.
+	ErrCapsExceeded = errors.New("total exceeds the sum of the caps")
 )
 
 /*
@@ -77,77 +227,163 @@ func IsGreaterThan(a, b *Money) bool {
 	return a.Nanos > b.Nanos
 }
 
-// fromInt will convert integer to google.Money
+// fromInt will convert integer to google.Money. currencyMultiplier is
+// expected to be a power of ten no greater than 1e9, so the conversion is
+// done in exact integer arithmetic. Any other multiplier gives nil.
 func fromInt(amount, currencyMultiplier int64, currencyCode string) *Money {
-	if amount == 0 {
-		return &Money{
-			CurrencyCode: currencyCode,
-			Units:        0,
-			Nanos:        0,
-		}
+	if !validCurrencyMultiplier(currencyMultiplier) {
+		return nil
 	}
-
-	nanos := float64(amount % currencyMultiplier)
-	nanosAdjusted := nanos * (math.Pow10(9) / float64(currencyMultiplier))
-	units := amount / currencyMultiplier
+	nanosPerMinorUnit := nanosMod / currencyMultiplier
 
 	return &Money{
 		CurrencyCode: currencyCode,
-		Units:        units,
-		Nanos:        int32(nanosAdjusted),
+		Units:        amount / currencyMultiplier,
+		Nanos:        int32(amount % currencyMultiplier * nanosPerMinorUnit),
 	}
 }
 
-// asInt will convert google.Money to integer
+// asInt will convert google.Money to integer, truncating nanos that don't
+// make up a whole minor unit. An invalid currencyMultiplier gives 0.
 func asInt(money *Money, currencyMultiplier int64) int64 {
-	if money == nil {
+	if money == nil || !validCurrencyMultiplier(currencyMultiplier) {
 		return 0
 	}
 
-	var nanosAdjusted int64
+	nanosPerMinorUnit := nanosMod / currencyMultiplier
+	return money.Units*currencyMultiplier + int64(money.Nanos)/nanosPerMinorUnit
+}
 
-	if money.Nanos == 0 {
-		nanosAdjusted = 0
-	} else {
-		nanosAdjustedFloat := float64(money.Nanos) * math.Pow10(-9) * float64(currencyMultiplier)
-		nanosAdjusted = int64(nanosAdjustedFloat)
+// RequiredExponent returns the number of decimal places actually used by the
+// nanos of m, ignoring trailing zeros: 0 for whole units, up to 9.
+func RequiredExponent(m *Money) int {
+	nanos := m.GetNanos()
+	if nanos < 0 {
+		nanos = -nanos
 	}
-
-	return money.Units*currencyMultiplier + nanosAdjusted
+	s := strings.TrimRight(fmt.Sprintf("%09d", nanos), "0")
+	return len(s)
 }
 
-// numDecPlaces returns the amount of decimals digits
-func numDecPlaces(v float64) int32 {
-	s := strconv.FormatFloat(v, 'f', -1, 64)
-	i := strings.IndexByte(s, '.')
-	if i > -1 {
-		return int32(len(s) - i - 1)
+// mulInt64 returns a*b and whether the product fits in an int64.
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if (c < 0) != ((a < 0) != (b < 0)) || c/b != a {
+		return 0, false
 	}
+	return c, true
+}
 
-	return 0
+// addInt64 returns a+b and whether the sum fits in an int64.
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	if (c > a) != (b > 0) {
+		return 0, false
+	}
+	return c, true
 }
 
-// FromInt64 will convert int64 value to google.Money ty
+// FromInt64 will convert int64 value to google.Money type. currencyMultiplier
+// must be a positive divisor of 1000000000 such as 100; for any other value
+// nil is returned. Use FromInt64Checked to get an error instead.
 func FromInt64(amount, currencyMultiplier int64, currencyCode string) *Money {
 	return fromInt(amount, currencyMultiplier, currencyCode)
 }
 
-// FromInt32 will convert int64 value to google.Money type
+// FromInt32 will convert int32 value to google.Money type. Like FromInt64 it
+// returns nil for an invalid currencyMultiplier.
 func FromInt32(amount, currencyMultiplier int32, currencyCode string) *Money {
 	return fromInt(int64(amount), int64(currencyMultiplier), currencyCode)
 }
 
-// AsInt32 will convert google.Money to int32
+// AsInt32 will convert google.Money to int32. It returns 0 for an invalid
+// currencyMultiplier; AsInt32Checked reports it as an error instead.
 func AsInt32(money *Money, currencyMultiplier int32) int32 {
 	moneyAsInt64 := asInt(money, int64(currencyMultiplier))
 	return int32(moneyAsInt64)
 }
 
-// AsInt64 will convert google.Money to int64
+// FromInt32Checked is like FromInt32 but rejects a currencyMultiplier that
+// isn't a positive divisor of 1000000000, for which minor units can't be
+// converted to nanos exactly. The amount itself always fits: an int32 number
+// of minor units never overflows the units of a Money.
+func FromInt32Checked(amount, currencyMultiplier int32, currencyCode string) (*Money, error) {
+	if !validCurrencyMultiplier(int64(currencyMultiplier)) {
+		return nil, ErrInvalidMultiplierProvided
+	}
+	return fromInt(int64(amount), int64(currencyMultiplier), currencyCode), nil
+}
+
+// AsInt32Checked is like AsInt32 but returns ErrOverflow instead of silently
+// truncating when the amount doesn't fit in an int32, and rejects a
+// currencyMultiplier that isn't a positive divisor of 1000000000.
+func AsInt32Checked(money *Money, currencyMultiplier int32) (int32, error) {
+	v, err := AsInt64Checked(money, int64(currencyMultiplier))
+	if err != nil {
+		return 0, err
+	}
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		return 0, ErrOverflow
+	}
+	return int32(v), nil
+}
+
+// validCurrencyMultiplier reports whether one nano-exact minor unit can be
+// derived from mult, i.e. mult is a positive divisor of 1000000000.
+func validCurrencyMultiplier(mult int64) bool {
+	return mult > 0 && nanosMod%mult == 0
+}
+
+// AsInt64 will convert google.Money to int64. currencyMultiplier must be a
+// positive divisor of 1000000000 such as 100; for any other value 0 is
+// returned. Use AsInt64Checked to get an error instead.
 func AsInt64(money *Money, currencyMultiplier int64) int64 {
 	return asInt(money, currencyMultiplier)
 }
 
+// FromInt64Checked is like FromInt64 but returns ErrInvalidMultiplierProvided
+// for a currencyMultiplier that isn't a positive divisor of 1000000000, so
+// that it can't be mistaken for a real result.
+func FromInt64Checked(amount, currencyMultiplier int64, currencyCode string) (*Money, error) {
+	if !validCurrencyMultiplier(currencyMultiplier) {
+		return nil, ErrInvalidMultiplierProvided
+	}
+	return fromInt(amount, currencyMultiplier, currencyCode), nil
+}
+
+// AsInt64Checked is like AsInt64 but returns ErrInvalidMultiplierProvided for
+// a currencyMultiplier that isn't a positive divisor of 1000000000 and
+// ErrOverflow instead of silently wrapping when the amount doesn't fit in an
+// int64.
+func AsInt64Checked(money *Money, currencyMultiplier int64) (int64, error) {
+	if !validCurrencyMultiplier(currencyMultiplier) {
+		return 0, ErrInvalidMultiplierProvided
+	}
+	if !IsValid(money) {
+		return 0, ErrInvalidValue
+	}
+	v, ok := mulInt64(money.GetUnits(), currencyMultiplier)
+	if ok {
+		v, ok = addInt64(v, int64(money.GetNanos())/(nanosMod/currencyMultiplier))
+	}
+	if !ok {
+		return 0, ErrOverflow
+	}
+	return v, nil
+}
+
+// Nearest returns the Money closest to target at nano precision, with halves
+// rounded to even. target is taken at its exact binary value.
+func Nearest(target float64, currencyCode string) (*Money, error) {
+	if math.IsNaN(target) || math.IsInf(target, 0) {
+		return nil, ErrInvalidValue
+	}
+	return fromRat(new(big.Rat).SetFloat64(target), currencyCode, HalfEven)
+}
+
 // IsValid checks if specified value has a valid units/nanos signs and ranges.
 func IsValid(m *Money) bool {
 	return signMatches(m) && validNanos(m.GetNanos())
@@ -161,10 +397,40 @@ func signMatches(m *Money) bool {
 // validNanos checks if given nanos are in range
 func validNanos(nanos int32) bool { return nanosMin <= nanos && nanos <= nanosMax }
 
+// ClampNanos repairs a value whose nanos sit exactly on the invalid
+// ±1000000000 boundary, as some external data does, by carrying them into
+// the units. Any other value, or one whose units can't take the carry, is
+// returned as an unchanged copy. A nil m gives nil.
+func ClampNanos(m *Money) *Money {
+	if m == nil {
+		return nil
+	}
+	res := &Money{Units: m.Units, Nanos: m.Nanos, CurrencyCode: m.CurrencyCode}
+	var carry int64
+	switch m.Nanos {
+	case nanosMod:
+		carry = 1
+	case -nanosMod:
+		carry = -1
+	default:
+		return res
+	}
+	if units, ok := addInt64(m.Units, carry); ok {
+		res.Units, res.Nanos = units, 0
+	}
+	return res
+}
+
 // IsPositive returns true if the specified money value is valid and is
 // positive.
 func IsPositive(m *Money) bool {
-	return IsValid(m) && m.GetUnits() > 0 || (m.GetUnits() == 0 && m.GetNanos() > 0)
+	return IsValid(m) && (m.GetUnits() > 0 || (m.GetUnits() == 0 && m.GetNanos() > 0))
+}
+
+// IsNegative returns true if the specified money value is valid and is
+// negative.
+func IsNegative(m *Money) bool {
+	return IsValid(m) && (m.GetUnits() < 0 || (m.GetUnits() == 0 && m.GetNanos() < 0))
 }
 
 // IsZero returns true if the specified money value is equal to zero.
@@ -172,267 +438,142 @@ func IsZero(m *Money) bool {
 	return m.GetUnits() == 0 && m.GetNanos() == 0
 }
 
+// IsReasonable reports whether m is valid and |units| <= maxUnits, e.g. to
+// flag a price of a billion dollars during ingestion. Only the whole units
+// count, so 10.000000001 is still within a bound of 10. It is a sanity check
+// rather than a correctness requirement.
+func IsReasonable(m *Money, maxUnits int64) bool {
+	if !IsValid(m) || maxUnits < 0 {
+		return false
+	}
+	units := m.GetUnits()
+	if units == math.MinInt64 {
+		return false
+	}
+	if units < 0 {
+		units = -units
+	}
+	return units <= maxUnits
+}
+
+// Mulv2 is like Mul but rounds the product to the nearest nano, with an exact
+// half rounded towards zero.
 func Mulv2(l *Money, r float64) (*Money, error) {
-	// fmt.Println("l, r", l, r)
+	return mulRat(l, r, HalfDown)
+}
+
+// MulNew is the same as Mul.
+func MulNew(l *Money, r float64) (*Money, error) {
+	return mulRat(l, r, TowardZero)
+}
+
+// Mul returns l multiplied by r, truncated towards zero to a whole nano. r is
+// taken as the decimal it prints as, so 0.07 is exactly 7/100, and the
+// product is computed exactly before it is truncated once. It returns
+// ErrOverflow only if the final product doesn't fit in a Money.
+func Mul(l *Money, r float64) (*Money, error) {
+	return mulRat(l, r, TowardZero)
+}
+
+// mulRat multiplies l by r exactly and rounds the product to a whole nano
+// according to mode.
+func mulRat(l *Money, r float64, mode RoundingMode) (*Money, error) {
 	// It does not make sense to allow multiplication of a price with a negative value as part of the existing flows.
 	// We decided because of that to return an error in case a negative value is provided.
-	if r < 0 {
+	if r < 0 || math.IsNaN(r) || math.IsInf(r, 0) {
 		return nil, ErrInvalidMultiplierProvided
 	}
-
 	if !IsValid(l) {
 		return nil, ErrInvalidValue
 	}
+	product := toRat(l)
+	return fromRat(product.Mul(product, ratFromFloat(r)), l.GetCurrencyCode(), mode)
+}
 
-	if IsZero(l) || r == float64(0) {
-		return &Money{
-			CurrencyCode: l.CurrencyCode,
-			Units:        0,
-			Nanos:        0,
-		}, nil
+// MulAs is like Mul but labels the result with currency, e.g. to give a value
+// read without a currency from the CSV path an explicit one. l must either
+// have no currency code or already be in currency.
+func MulAs(l *Money, r float64, currency string) (*Money, error) {
+	if !isValidCurrencyCode(currency) {
+		return nil, ErrInvalidCurrencyCode
 	}
-
-	multiplierDecPlaces := numDecPlaces(r)
-	powerOf10 := int64(math.Pow10(int(multiplierDecPlaces)))
-
-	intMulF, decMulF := math.Modf(r)
-	intMul, decMul := int64(intMulF), int64(decMulF*float64(powerOf10))
-
-	// To handle edge scenarios where `decMulF*float64(powerOf10)` returns different value than expected.
-	// For example: decMulF = 0.29 and powerOf10 = 100 should give 29 rather than 28.
-	// Ensure the following invariant is true: decMulF == float64(decMul) / float64(powerOf10)
-	// Increment decimal multipler (decMul) if deviation is >= 1%
-	newDecMulF := float64(decMul) / float64(powerOf10)
-	if newDecMulF < decMulF {
-		percentageChange := ((decMulF - newDecMulF) / decMulF) * 100
-		if percentageChange >= 0.01 {
-			decMul++
-		}
+	if _, err := matchCurrency(l.GetCurrencyCode(), currency); err != nil {
+		return nil, err
 	}
-
-	// multiply both sections
-	nanosMultiplied := int64(l.GetNanos()) * intMul
-	if decMul != 0 {
-		// For expressions like: a * b / c, if a and b are of type int64,
-		// then a * b could possibly exceed valid int64 range.
-		// To handle such scenarios and retain best value,
-		// perform division followed by multiplication: a * (b / c)
-		nanosMulF := float64(l.GetNanos()) * (float64(decMul) / float64(powerOf10))
-		nanosMul := int64(nanosMulF)
-		nanosMulDelta := nanosMulF - float64(nanosMul)
-		if nanosMulDelta > 0.5 {
-			nanosMul++
-		}
-		// nanosMultiplied += int64(float64(l.GetNanos()) * (float64(decMul) / float64(powerOf10)))
-		nanosMultiplied += nanosMul
-	}
-
-	intUnitsMultiplied := l.GetUnits() * intMul
-	decUnitsMultiplied := int64(0)
-	if decMul != 0 {
-		intUnitsMultiplied += int64(float64(l.GetUnits()*decMul) / float64(powerOf10))
-		decUnitsMultiplied = l.GetUnits() * decMul % powerOf10
-	}
-
-	// For multiplication operations, if one operand is small (of the order 1e-08),
-	//then int64 conversion would result in value 0.
-	// For example, int64(math.Pow10(9)/float64(powerOf10)) would be 0.
-	// To handle such scenarios and retain best value, perform multiplication across all operands involved,
-	// followed by casting to int64.
-	nanosDecUnitAdjusted := int64(float64(decUnitsMultiplied) * math.Pow10(9) / float64(powerOf10))
-
-	units := intUnitsMultiplied
-	nanos := nanosDecUnitAdjusted + nanosMultiplied
-
-	if (units >= 0 && nanos >= 0) || (units < 0 && nanos <= 0) {
-		// same sign <units, nanos>
-		units += nanos / nanosMod
-		nanos = nanos % nanosMod
-	} else {
-		// different sign. nanos guaranteed to not to go over the limit
-		if units > 0 {
-			units--
-			nanos += nanosMod
-		} else if units < 0 {
-			units++
-			nanos -= nanosMod
-		}
+	res, err := Mul(l, r)
+	if err != nil {
+		return nil, err
 	}
-
-	return &Money{
-		Units:        units,
-		Nanos:        int32(nanos),
-		CurrencyCode: l.GetCurrencyCode()}, nil
+	res.CurrencyCode = currency
+	return res, nil
 }
 
-func MulNew(l *Money, r float64) (*Money, error) {
-	// It does not make sense to allow multiplication of a price with a negative value as part of the existing flows.
-	// We decided because of that to return an error in case a negative value is provided.
-	if r < 0 {
-		return nil, ErrInvalidMultiplierProvided
-	}
-	if !IsValid(l) {
+// MulCapped is like Mul but returns limit instead of any product that would
+// exceed it, guarding against runaway computed prices.
+func MulCapped(l *Money, r float64, limit *Money) (*Money, error) {
+	if limit == nil || !IsValid(limit) {
 		return nil, ErrInvalidValue
 	}
-	if IsZero(l) || r == float64(0) {
-		return &Money{
-			CurrencyCode: l.CurrencyCode,
-			Units:        0,
-			Nanos:        0,
-		}, nil
-	}
-
-	multiplierDecPlaces := numDecPlaces(r)
-	powerOf10 := int64(math.Pow10(int(multiplierDecPlaces)))
-
-	intMulF, decMulF := math.Modf(r)
-	intMul, decMul := int64(intMulF), int64(decMulF*float64(powerOf10))
-	// To handle edge scenarios where `decMulF*float64(powerOf10)` returns different value than expected.
-	// For example: decMulF = 0.29 and powerOf10 = 100 should give 29 rather than 28.
-	// Ensure the following invariant is true: decMulF == float64(decMul) / float64(powerOf10)
-	// Increment decimal multipler (decMul) if deviation is >= 1%
-	newDecMulF := float64(decMul) / float64(powerOf10)
-	if newDecMulF < decMulF {
-		percentageChange := ((decMulF - newDecMulF) / decMulF) * 100
-		if percentageChange >= 1 {
-			decMul++
-		}
+	code, err := matchCurrency(l.GetCurrencyCode(), limit.CurrencyCode)
+	if err != nil {
+		return nil, err
 	}
-	// multiply both sections
-	nanosMultiplied := int64(l.GetNanos()) * intMul
-	if decMul != 0 {
-		// For expressions like: a * b / c, if a and b are of type int64,
-		// then a * b could possibly exceed valid int64 range.
-		// To handle such scenarios and retain best value,
-		// perform division followed by multiplication: a * (b / c)
-		nanosMultiplied += int64(float64(l.GetNanos()) * (float64(decMul) / float64(powerOf10)))
-	}
-
-	intUnitsMultiplied := l.GetUnits() * intMul
-	decUnitsMultiplied := int64(0)
-	if decMul != 0 {
-		intUnitsMultiplied += int64(float64(l.GetUnits()*decMul) / float64(powerOf10))
-		decUnitsMultiplied = l.GetUnits() * decMul % powerOf10
-	}
-
-	// For multiplication operations, if one operand is small (of the order 1e-08),
-	//then int64 conversion would result in value 0.
-	// For example, int64(math.Pow10(9)/float64(powerOf10)) would be 0.
-	// To handle such scenarios and retain best value, perform multiplication across all operands involved,
-	// followed by casting to int64.
-	nanosDecUnitAdjusted := int64(float64(decUnitsMultiplied) * math.Pow10(9) / float64(powerOf10))
-
-	units := intUnitsMultiplied
-	nanos := nanosDecUnitAdjusted + nanosMultiplied
-	if (units >= 0 && nanos >= 0) || (units < 0 && nanos <= 0) {
-		// same sign <units, nanos>
-		units += nanos / nanosMod
-		nanos = nanos % nanosMod
-	} else {
-		// different sign. nanos guaranteed to not to go over the limit
-		if units > 0 {
-			units--
-			nanos += nanosMod
-		} else if units < 0 {
-			units++
-			nanos -= nanosMod
-		}
+	res, err := Mul(l, r)
+	if err != nil {
+		return nil, err
 	}
-	return &Money{
-		Units:        units,
-		Nanos:        int32(nanos),
-		CurrencyCode: l.GetCurrencyCode()}, nil
+	if Compare(res, limit) > 0 {
+		res = &Money{Units: limit.Units, Nanos: limit.Nanos}
+	}
+	res.CurrencyCode = code
+	return res, nil
 }
 
-func Mul(l *Money, r float64) (*Money, error) {
-	//fmt.Println("input:", l, r)
-	// It does not make sense to allow multiplication of a price with a negative value as part of the existing flows.
-	// We decided because of that to return an error in case a negative value is provided.
-	if r < 0 {
+// MulToMinorUnits multiplies l by r and rounds the exact product straight to
+// the minor units of the currency of l according to mode. Rounding once
+// avoids the double rounding of Mul, which already cuts the product to whole
+// nanos, followed by a separate rounding to minor units.
+func MulToMinorUnits(l *Money, r float64, mode RoundingMode) (*Money, error) {
+	if r < 0 || math.IsNaN(r) || math.IsInf(r, 0) {
 		return nil, ErrInvalidMultiplierProvided
 	}
-
+	if !mode.valid() {
+		return nil, ErrInvalidRoundingMode
+	}
+	mult, err := MultiplierFor(l.GetCurrencyCode())
+	if err != nil {
+		return nil, err
+	}
 	if !IsValid(l) {
 		return nil, ErrInvalidValue
 	}
 
-	if IsZero(l) || r == float64(0) {
-		return &Money{
-			CurrencyCode: l.CurrencyCode,
-			Units:        0,
-			Nanos:        0,
-		}, nil
-	}
-
-	multiplierDecPlaces := numDecPlaces(r)
-	powerOf10 := int32(math.Pow10(int(multiplierDecPlaces)))
-	//fmt.Println("power:", multiplierDecPlaces, powerOf10)
-
-	intMulF, decMulF := math.Modf(r)
-	intMul, decMul := int64(intMulF), int64(decMulF*float64(powerOf10))
-
-	//fmt.Println("f:", intMulF, decMulF, intMul, decMul)
+	product := toRat(l)
+	product.Mul(product, ratFromFloat(r))
+	minor := roundQuo(new(big.Int).Mul(product.Num(), big.NewInt(mult)), product.Denom(), mode)
+	return fromNanos(minor.Mul(minor, big.NewInt(nanosMod/mult)), l.GetCurrencyCode())
+}
 
-	// To handle edge scenarios where `decMulF*float64(powerOf10)` returns different value than expected.
-	// For example: decMulF = 0.29 and powerOf10 = 100 should give 29 rather than 28.
-	// Ensure the following invariant is true: decMulF == float64(decMul) / float64(powerOf10)
-	// Increment decimal multipler (decMul) if deviation is >= 1%
-	newDecMulF := float64(decMul) / float64(powerOf10)
-	if newDecMulF < decMulF {
-		percentageChange := ((decMulF - newDecMulF) / decMulF) * 100
-		if percentageChange >= 1 {
-			decMul++
-		}
+// Div divides m by divisor, the inverse of Mul, and rounds the exact
+// quotient to a whole nano according to mode. divisor is taken as the
+// decimal it prints as, so dividing by 0.1 is exact. Like Mul it rejects a
+// negative or non-finite divisor with ErrInvalidMultiplierProvided, and it
+// returns ErrDivisionByZero for a zero divisor.
+func Div(m *Money, divisor float64, mode RoundingMode) (*Money, error) {
+	if divisor < 0 || math.IsNaN(divisor) || math.IsInf(divisor, 0) {
+		return nil, ErrInvalidMultiplierProvided
 	}
-
-	//fmt.Println("new:", newDecMulF, decMul)
-
-	// multiply both sections
-	nanosMultiplied := int64(l.GetNanos()) * intMul
-	//fmt.Println("nanosmultiplied1:", nanosMultiplied)
-
-	if decMul != 0 {
-		nanosMultiplied += int64(l.GetNanos()) * decMul / int64(powerOf10)
-		//fmt.Println("nanosmultiplied2:", nanosMultiplied, decMul, int64(powerOf10), decMul/int64(powerOf10), int64(l.GetNanos())*decMul/int64(powerOf10))
-
+	if divisor == 0 {
+		return nil, ErrDivisionByZero
 	}
-
-	intUnitsMultiplied := l.GetUnits() * intMul
-	decUnitsMultiplied := int64(0)
-	if decMul != 0 {
-		intUnitsMultiplied += int64(float64(l.GetUnits()*decMul) / float64(powerOf10))
-		decUnitsMultiplied = l.GetUnits() * decMul % int64(powerOf10)
+	if !mode.valid() {
+		return nil, ErrInvalidRoundingMode
 	}
-
-	nanosDecUnitAdjusted := decUnitsMultiplied * int64(math.Pow10(9)/float64(powerOf10))
-
-	//fmt.Println("adjusted:", nanosDecUnitAdjusted, decUnitsMultiplied)
-
-	units := intUnitsMultiplied
-	nanos := nanosDecUnitAdjusted + nanosMultiplied
-
-	//fmt.Println("final:", units, nanos)
-
-	if (units >= 0 && nanos >= 0) || (units < 0 && nanos <= 0) {
-		// same sign <units, nanos>
-		units += nanos / nanosMod
-		nanos = nanos % nanosMod
-	} else {
-		// different sign. nanos guaranteed to not to go over the limit
-		if units > 0 {
-			units--
-			nanos += nanosMod
-		} else if units < 0 {
-			units++
-			nanos -= nanosMod
-		}
+	if !IsValid(m) {
+		return nil, ErrInvalidValue
 	}
-	//fmt.Println("final corrected:", units, nanos)
-
-	return &Money{
-		Units:        units,
-		Nanos:        int32(nanos),
-		CurrencyCode: l.GetCurrencyCode()}, nil
+	quotient := toRat(m)
+	return fromRat(quotient.Quo(quotient, ratFromFloat(divisor)), m.GetCurrencyCode(), mode)
 }
 
 func generateMicro() {
@@ -478,54 +619,22 @@ func convertNanos(val string) int32 {
 	return int32(i)
 }
 
-func convertToMoney(val string) *Money {
-	vals := strings.Split(val, ".")
-	if len(vals) == 1 {
-		vals = append(vals, "")
-	}
-	units, _ := strconv.ParseInt(vals[0], 10, 64)
-	return &Money{
-		Units:        units,
-		Nanos:        convertNanos(vals[1]),
-		CurrencyCode: "",
-	}
-}
-
 func ReadCsvFile(filePath string, offset int) {
 	// Load a csv file.
-	f, _ := os.Open(filePath)
-
-	// Create a new reader.
-	r := csv.NewReader(f)
-	for {
-		record, err := r.Read()
-		// Stop at EOF.
-		if err == io.EOF {
-			break
-		}
+	f, err := os.Open(filePath)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer f.Close()
 
-		if err != nil {
-			panic(err)
-		}
-		// Display record.
-		// ... Display record length.
-		// ... Display all individual elements of the slice.
-		//fmt.Println(record)
-		m := convertToMoney(record[0+offset])
-		vat, _ := strconv.ParseFloat(record[1+offset], 64)
-		expected := convertToMoney(record[2+offset])
-		//fmt.Println("input:", m, vat)
-		res, err := Mulv2(m, vat)
-		if err != nil {
-			fmt.Printf(err.Error())
-			continue
-		}
-		if res.Units != expected.Units || res.Nanos != expected.Nanos {
-			fmt.Println(m, vat, res, expected)
-		} else {
-			//fmt.Println("success: ", m, vat, res, expected)
-		}
-		//time.Sleep(1 * time.Second)
+	opts := CsvOptions{
+		Offset:         offset,
+		ErrorMode:      CollectAll,
+		ToleranceNanos: csvToleranceNanos,
+	}
+	if _, err := VerifyCsv(f, os.Stdout, opts); err != nil {
+		fmt.Println(err)
 	}
 }
 
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -58,6 +60,688 @@ func TestDivideBy100(t *testing.T) {
 		}
 	}
 }
+
+func TestRequiredExponent(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		expected int
+	}{
+		{&Money{Units: 5, Nanos: 0}, 0},
+		{&Money{Units: 5, Nanos: 500000000}, 1},
+		{&Money{Units: 0, Nanos: 130000000}, 2},
+		{&Money{Units: 0, Nanos: 123456789}, 9},
+		{&Money{Units: -1, Nanos: -1000}, 6},
+		{nil, 0},
+	}
+
+	for _, v := range cases {
+		if res := RequiredExponent(v.input); res != v.expected {
+			t.Errorf("%v: got:%d expected:%d", v.input, res, v.expected)
+		}
+	}
+}
+
+func TestMulRejectsNonFiniteMultiplier(t *testing.T) {
+	l := &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}
+	muls := map[string]func(*Money, float64) (*Money, error){
+		"Mul":    Mul,
+		"Mulv2":  Mulv2,
+		"MulNew": MulNew,
+	}
+
+	for name, mul := range muls {
+		for _, r := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+			res, err := mul(l, r)
+			if err != ErrInvalidMultiplierProvided {
+				t.Errorf("%s(%v): got %v, %v expected ErrInvalidMultiplierProvided", name, r, res, err)
+			}
+		}
+	}
+}
+
+func TestMulDecimalMultiplier(t *testing.T) {
+	cases := []struct {
+		l        *Money
+		r        float64
+		expected *Money
+	}{
+		{&Money{Units: 100, CurrencyCode: "USD"}, 0.29, &Money{Units: 29, CurrencyCode: "USD"}},
+		{&Money{Units: 100, CurrencyCode: "USD"}, 0.07, &Money{Units: 7, CurrencyCode: "USD"}},
+		{&Money{Units: 1000, CurrencyCode: "USD"}, 1.101, &Money{Units: 1101, CurrencyCode: "USD"}},
+		{&Money{Units: 1000, CurrencyCode: "USD"}, 12.103, &Money{Units: 12103, CurrencyCode: "USD"}},
+		{&Money{Units: 1000, CurrencyCode: "USD"}, 1.0001, &Money{Units: 1000, Nanos: 100000000, CurrencyCode: "USD"}},
+	}
+	muls := map[string]func(*Money, float64) (*Money, error){
+		"Mul":    Mul,
+		"Mulv2":  Mulv2,
+		"MulNew": MulNew,
+	}
+
+	for name, mul := range muls {
+		for _, v := range cases {
+			res, err := mul(v.l, v.r)
+			if err != nil {
+				t.Errorf("%s(%v, %v): unexpected error %v", name, v.l, v.r, err)
+				continue
+			}
+			if *res != *v.expected {
+				t.Errorf("%s(%v, %v): got %v expected %v", name, v.l, v.r, res, v.expected)
+			}
+		}
+	}
+}
+
+func TestMulLongDecimalMultiplier(t *testing.T) {
+	cases := []struct {
+		l         *Money
+		r         float64
+		truncated *Money
+		rounded   *Money
+	}{
+		{&Money{Units: 1}, 0.1234567891, &Money{Nanos: 123456789}, &Money{Nanos: 123456789}},
+		{&Money{Units: 19, Nanos: 130000000}, 0.1234567891, &Money{Units: 2, Nanos: 361728375}, &Money{Units: 2, Nanos: 361728375}},
+		{&Money{Nanos: 999999999}, 1.123456789012, &Money{Units: 1, Nanos: 123456787}, &Money{Units: 1, Nanos: 123456788}},
+		{&Money{Units: -3, Nanos: -999999999}, 0.12345678901234, &Money{Nanos: -493827155}, &Money{Nanos: -493827156}},
+		{&Money{Units: 1000000, Nanos: 999999999}, 1.00000000001, &Money{Units: 1000001, Nanos: 9999}, &Money{Units: 1000001, Nanos: 9999}},
+	}
+	muls := map[string]func(*Money, float64) (*Money, error){
+		"Mul":    Mul,
+		"Mulv2":  Mulv2,
+		"MulNew": MulNew,
+	}
+
+	for name, mul := range muls {
+		for _, v := range cases {
+			expected := v.truncated
+			if name == "Mulv2" {
+				expected = v.rounded
+			}
+			res, err := mul(v.l, v.r)
+			if err != nil {
+				t.Errorf("%s(%v, %v): unexpected error %v", name, v.l, v.r, err)
+				continue
+			}
+			if *res != *expected {
+				t.Errorf("%s(%v, %v): got %v expected %v", name, v.l, v.r, res, expected)
+			}
+		}
+	}
+}
+
+func TestMulOverflow(t *testing.T) {
+	cases := []struct {
+		l        *Money
+		r        float64
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: math.MaxInt64 / 2, Nanos: 500000000}, 3.0, nil, ErrOverflow},
+		{&Money{Units: math.MaxInt64 / 2, Nanos: 500000000}, 2.5, nil, ErrOverflow},
+		{&Money{Units: math.MaxInt64, Nanos: 600000000}, 1.0, &Money{Units: math.MaxInt64, Nanos: 600000000}, nil},
+		{&Money{Units: math.MaxInt64 / 2}, 2.0, &Money{Units: math.MaxInt64 - 1}, nil},
+		{&Money{Units: math.MinInt64 / 2}, 2.0, &Money{Units: math.MinInt64}, nil},
+		{&Money{Units: math.MinInt64 / 2, Nanos: -1}, 2.0, &Money{Units: math.MinInt64, Nanos: -2}, nil},
+		{&Money{Units: math.MinInt64 / 2, Nanos: -600000000}, 2.0, nil, ErrOverflow},
+		// Only the final product counts: intermediate values of a long rate
+		// such as 10/3 must not be reported as an overflow.
+		{&Money{Units: 123456, Nanos: 789000000}, 10.0 / 3, &Money{Units: 411522, Nanos: 630000000}, nil},
+		{&Money{Units: 1000000000}, 1.0 / 3, &Money{Units: 333333333, Nanos: 333333300}, nil},
+		{&Money{Units: math.MaxInt64 / 4}, 3.5, &Money{Units: 8070450532247928828, Nanos: 500000000}, nil},
+		{&Money{Units: math.MaxInt64, Nanos: 999999999}, 1.0000000000000002, nil, ErrOverflow},
+	}
+	muls := map[string]func(*Money, float64) (*Money, error){
+		"Mul":    Mul,
+		"Mulv2":  Mulv2,
+		"MulNew": MulNew,
+	}
+
+	for name, mul := range muls {
+		for _, v := range cases {
+			res, err := mul(v.l, v.r)
+			if err != v.err {
+				t.Errorf("%s(%+v, %v): got %v, %v expected error %v", name, *v.l, v.r, res, err, v.err)
+				continue
+			}
+			if v.expected != nil && *res != *v.expected {
+				t.Errorf("%s(%+v, %v): got %+v expected %+v", name, *v.l, v.r, *res, *v.expected)
+			}
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	cases := []struct {
+		target   float64
+		expected *Money
+		err      error
+	}{
+		{19.13, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, nil},
+		{-0.1, &Money{Units: 0, Nanos: -100000000, CurrencyCode: "USD"}, nil},
+		// 1/1024 is 976562.5 nanos and rounds down to the even neighbour.
+		{1.0 / 1024, &Money{Units: 0, Nanos: 976562, CurrencyCode: "USD"}, nil},
+		// 3/1024 is 2929687.5 nanos and rounds up to the even neighbour.
+		{3.0 / 1024, &Money{Units: 0, Nanos: 2929688, CurrencyCode: "USD"}, nil},
+		{-3.0 / 1024, &Money{Units: 0, Nanos: -2929688, CurrencyCode: "USD"}, nil},
+		{1e300, nil, ErrOverflow},
+		{math.NaN(), nil, ErrInvalidValue},
+		{math.Inf(-1), nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := Nearest(v.target, "USD")
+		if err != v.err {
+			t.Errorf("%v: got error %v expected %v", v.target, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v: got %v expected %v", v.target, res, v.expected)
+		}
+	}
+}
+
+func TestFromInt64AsInt64RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, mult := range []int64{1, 100, 1000} {
+		for i := 0; i < 10000; i++ {
+			x := rng.Int63()
+			if i%2 == 1 {
+				x = -x
+			}
+			m := FromInt64(x, mult, "USD")
+			if !IsValid(m) {
+				t.Fatalf("FromInt64(%d, %d) = %+v is invalid", x, mult, *m)
+			}
+			if res := AsInt64(m, mult); res != x {
+				t.Fatalf("AsInt64(FromInt64(%d, %d)) = %d", x, mult, res)
+			}
+		}
+	}
+
+	cases := []struct {
+		amount, mult int64
+		expected     *Money
+	}{
+		{1913, 100, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}},
+		{-1913, 100, &Money{Units: -19, Nanos: -130000000, CurrencyCode: "USD"}},
+		{math.MaxInt64, 1000, &Money{Units: math.MaxInt64 / 1000, Nanos: 807000000, CurrencyCode: "USD"}},
+		{0, 100, &Money{CurrencyCode: "USD"}},
+	}
+	for _, v := range cases {
+		if res := FromInt64(v.amount, v.mult, "USD"); *res != *v.expected {
+			t.Errorf("FromInt64(%d, %d) got %+v expected %+v", v.amount, v.mult, *res, *v.expected)
+		}
+	}
+}
+
+func TestFromInt64AsInt64InvalidMultiplier(t *testing.T) {
+	for _, mult := range []int64{0, -100, 3, 1e10, math.MinInt64} {
+		if res := FromInt64(1913, mult, "USD"); res != nil {
+			t.Errorf("FromInt64(1913, %d) got %v expected nil", mult, res)
+		}
+		if res := AsInt64(&Money{Units: 1}, mult); res != 0 {
+			t.Errorf("AsInt64(1, %d) got %d expected 0", mult, res)
+		}
+	}
+	if res := FromInt32(1913, 0, "USD"); res != nil {
+		t.Errorf("FromInt32(1913, 0) got %v expected nil", res)
+	}
+	if res := AsInt32(&Money{Units: 1}, 0); res != 0 {
+		t.Errorf("AsInt32(1, 0) got %d expected 0", res)
+	}
+}
+
+func TestMajorUnitsMinorUnitsFraction(t *testing.T) {
+	cases := []struct {
+		input *Money
+		major int64
+		minor int32
+	}{
+		{&Money{Units: 19, Nanos: 130000000}, 19, 130000000},
+		{&Money{Units: -19, Nanos: -130000000}, -19, -130000000},
+		{&Money{Units: 0, Nanos: -500000000}, 0, -500000000},
+		{&Money{Units: -1, Nanos: 500000000}, 0, -500000000},
+		{&Money{Units: 1, Nanos: -250000000}, 0, 750000000},
+		{&Money{Units: -2, Nanos: 1000000000}, -1, 0},
+		{&Money{Units: math.MaxInt64, Nanos: 999999999}, math.MaxInt64, 999999999},
+		{nil, 0, 0},
+	}
+
+	for _, v := range cases {
+		if major, minor := v.input.MajorUnits(), v.input.MinorUnitsFraction(); major != v.major || minor != v.minor {
+			t.Errorf("%+v: got %d, %d expected %d, %d", v.input, major, minor, v.major, v.minor)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		units    int64
+		nanos    int32
+		expected *Money
+		err      error
+	}{
+		{19, 130000000, &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, nil},
+		{0, 123000000, &Money{Nanos: 123000000, CurrencyCode: "USD"}, nil},
+		{0, 1, &Money{Nanos: 1, CurrencyCode: "USD"}, nil},
+		{1, -1, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := New(v.units, v.nanos, "USD")
+		if err != v.err {
+			t.Errorf("New(%d, %d): got error %v expected %v", v.units, v.nanos, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("New(%d, %d): got %v expected %v", v.units, v.nanos, res, v.expected)
+		}
+	}
+}
+
+func TestMaxPrecision(t *testing.T) {
+	defer func(p int) { MaxPrecision = p }(MaxPrecision)
+	MaxPrecision = 2
+
+	if _, err := New(0, 123000000, "USD"); err != ErrPrecisionExceeded {
+		t.Errorf("New: got error %v expected %v", err, ErrPrecisionExceeded)
+	}
+	if _, err := New(19, 130000000, "USD"); err != nil {
+		t.Errorf("New: got error %v expected none", err)
+	}
+	if _, err := Parse("0.123", "USD"); err != ErrPrecisionExceeded {
+		t.Errorf("Parse: got error %v expected %v", err, ErrPrecisionExceeded)
+	}
+	if _, err := Parse("0.120000", "USD"); err != nil {
+		t.Errorf("Parse: got error %v expected none", err)
+	}
+
+	MaxPrecision = 0
+	if _, err := Parse("19.13", "USD"); err != ErrPrecisionExceeded {
+		t.Errorf("Parse fast path: got error %v expected %v", err, ErrPrecisionExceeded)
+	}
+
+	for _, p := range []int{-1, 10} {
+		MaxPrecision = p
+		if _, err := New(5, 0, "USD"); err != ErrInvalidPlaces {
+			t.Errorf("MaxPrecision %d: New got error %v expected %v", p, err, ErrInvalidPlaces)
+		}
+		if _, err := Parse("5", "USD"); err != ErrInvalidPlaces {
+			t.Errorf("MaxPrecision %d: Parse got error %v expected %v", p, err, ErrInvalidPlaces)
+		}
+	}
+}
+
+func TestAsInt32Checked(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		mult     int32
+		expected int32
+		err      error
+	}{
+		{&Money{Units: 21474836, Nanos: 470000000}, 100, math.MaxInt32, nil},
+		{&Money{Units: 21474836, Nanos: 480000000}, 100, 0, ErrOverflow},
+		{&Money{Units: -21474836, Nanos: -480000000}, 100, math.MinInt32, nil},
+		{&Money{Units: -21474836, Nanos: -490000000}, 100, 0, ErrOverflow},
+		{&Money{Units: math.MaxInt32, Nanos: 999999999}, 1, math.MaxInt32, nil},
+		{&Money{Units: math.MaxInt64}, 100, 0, ErrOverflow},
+		{&Money{Units: 19, Nanos: 139000000}, 100, 1913, nil},
+		{nil, 100, 0, nil},
+		{&Money{Units: 1}, 0, 0, ErrInvalidMultiplierProvided},
+		{&Money{Units: 1}, 3, 0, ErrInvalidMultiplierProvided},
+		{&Money{Units: 1, Nanos: -1}, 100, 0, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := AsInt32Checked(v.input, v.mult)
+		if err != v.err || res != v.expected {
+			t.Errorf("AsInt32Checked(%v, %d): got %d, %v expected %d, %v", v.input, v.mult, res, err, v.expected, v.err)
+		}
+	}
+}
+
+func TestFromInt32Checked(t *testing.T) {
+	res, err := FromInt32Checked(math.MaxInt32, 100, "USD")
+	expected := &Money{Units: 21474836, Nanos: 470000000, CurrencyCode: "USD"}
+	if err != nil || *res != *expected {
+		t.Errorf("got %v, %v expected %v", res, err, expected)
+	}
+	if back, err := AsInt32Checked(res, 100); err != nil || back != math.MaxInt32 {
+		t.Errorf("round trip got %d, %v expected %d", back, err, math.MaxInt32)
+	}
+
+	for _, mult := range []int32{0, -100, 3, 7} {
+		if _, err := FromInt32Checked(1, mult, "USD"); err != ErrInvalidMultiplierProvided {
+			t.Errorf("multiplier %d: got error %v expected %v", mult, err, ErrInvalidMultiplierProvided)
+		}
+	}
+}
+
+func TestFromInt64AsInt64Checked(t *testing.T) {
+	res, err := FromInt64Checked(math.MaxInt64, 1000, "USD")
+	expected := &Money{Units: math.MaxInt64 / 1000, Nanos: 807000000, CurrencyCode: "USD"}
+	if err != nil || *res != *expected {
+		t.Errorf("got %v, %v expected %v", res, err, expected)
+	}
+	if back, err := AsInt64Checked(res, 1000); err != nil || back != math.MaxInt64 {
+		t.Errorf("round trip got %d, %v expected %d", back, err, int64(math.MaxInt64))
+	}
+	if res, err := FromInt64Checked(0, 100, "USD"); err != nil || *res != (Money{CurrencyCode: "USD"}) {
+		t.Errorf("zero got %v, %v expected USD 0", res, err)
+	}
+
+	cases := []struct {
+		input    *Money
+		mult     int64
+		expected int64
+		err      error
+	}{
+		{&Money{Units: 19, Nanos: 139000000}, 100, 1913, nil},
+		{&Money{Units: math.MaxInt64 / 100, Nanos: 70000000}, 100, math.MaxInt64, nil},
+		{&Money{Units: math.MaxInt64 / 100, Nanos: 80000000}, 100, 0, ErrOverflow},
+		{&Money{Units: math.MaxInt64}, 100, 0, ErrOverflow},
+		{&Money{Units: math.MinInt64 / 100, Nanos: -80000000}, 100, math.MinInt64, nil},
+		{nil, 100, 0, nil},
+		{&Money{Units: 1, Nanos: -1}, 100, 0, ErrInvalidValue},
+	}
+	for _, v := range cases {
+		res, err := AsInt64Checked(v.input, v.mult)
+		if err != v.err || res != v.expected {
+			t.Errorf("AsInt64Checked(%v, %d): got %d, %v expected %d, %v", v.input, v.mult, res, err, v.expected, v.err)
+		}
+	}
+
+	for _, mult := range []int64{0, -100, 3, 1e10} {
+		if _, err := FromInt64Checked(1, mult, "USD"); err != ErrInvalidMultiplierProvided {
+			t.Errorf("FromInt64Checked multiplier %d: got error %v expected %v", mult, err, ErrInvalidMultiplierProvided)
+		}
+		if _, err := AsInt64Checked(&Money{Units: 1}, mult); err != ErrInvalidMultiplierProvided {
+			t.Errorf("AsInt64Checked multiplier %d: got error %v expected %v", mult, err, ErrInvalidMultiplierProvided)
+		}
+	}
+}
+
+func TestMulAs(t *testing.T) {
+	cases := []struct {
+		l        *Money
+		r        float64
+		currency string
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 19}, 1.2, "USD", &Money{Units: 22, Nanos: 800000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 19, CurrencyCode: "USD"}, 1.2, "USD", &Money{Units: 22, Nanos: 800000000, CurrencyCode: "USD"}, nil},
+		{&Money{}, 1.2, "EUR", &Money{CurrencyCode: "EUR"}, nil},
+		{&Money{Units: 19, CurrencyCode: "EUR"}, 1.2, "USD", nil, ErrMismatchingCurrency},
+		{&Money{Units: 19}, 1.2, "", nil, ErrInvalidCurrencyCode},
+		{&Money{Units: 19}, 1.2, "usd", nil, ErrInvalidCurrencyCode},
+		{&Money{Units: 19}, -1, "USD", nil, ErrInvalidMultiplierProvided},
+	}
+
+	for _, v := range cases {
+		res, err := MulAs(v.l, v.r, v.currency)
+		if err != v.err {
+			t.Errorf("MulAs(%v, %v, %q): got error %v expected %v", v.l, v.r, v.currency, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("MulAs(%v, %v, %q): got %v expected %v", v.l, v.r, v.currency, res, v.expected)
+		}
+	}
+}
+
+func TestIsReasonable(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		maxUnits int64
+		expected bool
+	}{
+		{&Money{Units: 999, Nanos: 990000000, CurrencyCode: "USD"}, 1000, true},
+		{&Money{Units: 1000, CurrencyCode: "USD"}, 1000, true},
+		{&Money{Units: 1000, Nanos: 1, CurrencyCode: "USD"}, 1000, true},
+		{&Money{Units: 1001, CurrencyCode: "USD"}, 1000, false},
+		{&Money{Units: -1000, Nanos: -999999999, CurrencyCode: "USD"}, 1000, true},
+		{&Money{Units: -1000000000, CurrencyCode: "USD"}, 1000, false},
+		{&Money{Units: 10, Nanos: 1}, 10, true},
+		{&Money{Units: math.MaxInt64, Nanos: 1}, math.MaxInt64, true},
+		{&Money{Units: math.MinInt64}, math.MaxInt64, false},
+		{nil, 0, true},
+		{&Money{Units: 1}, -1, false},
+		{&Money{Units: 1, Nanos: -1}, 1000, false},
+	}
+
+	for _, v := range cases {
+		if res := IsReasonable(v.input, v.maxUnits); res != v.expected {
+			t.Errorf("IsReasonable(%v, %d) got:%v expected:%v", v.input, v.maxUnits, res, v.expected)
+		}
+	}
+}
+
+func TestIsPositiveIsNegative(t *testing.T) {
+	cases := []struct {
+		input              *Money
+		positive, negative bool
+	}{
+		{&Money{Units: 1}, true, false},
+		{&Money{Nanos: 1}, true, false},
+		{&Money{Units: -1}, false, true},
+		{&Money{Nanos: -1}, false, true},
+		{&Money{}, false, false},
+		{nil, false, false},
+		{&Money{Nanos: 1000000000}, false, false},
+		{&Money{Nanos: -1000000000}, false, false},
+		{&Money{Units: 1, Nanos: -1}, false, false},
+		{&Money{Units: -1, Nanos: 1}, false, false},
+	}
+
+	for _, v := range cases {
+		if res := IsPositive(v.input); res != v.positive {
+			t.Errorf("IsPositive(%+v) got:%v expected:%v", v.input, res, v.positive)
+		}
+		if res := IsNegative(v.input); res != v.negative {
+			t.Errorf("IsNegative(%+v) got:%v expected:%v", v.input, res, v.negative)
+		}
+	}
+}
+
+func TestMulCapped(t *testing.T) {
+	limit := &Money{Units: 25, CurrencyCode: "USD"}
+	cases := []struct {
+		l        *Money
+		r        float64
+		limit    *Money
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 19, CurrencyCode: "USD"}, 1.2, limit, &Money{Units: 22, Nanos: 800000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 19, CurrencyCode: "USD"}, 2, limit, &Money{Units: 25, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 25, CurrencyCode: "USD"}, 1, limit, &Money{Units: 25, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 19}, 2, limit, &Money{Units: 25, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 19, CurrencyCode: "EUR"}, 1.2, limit, nil, ErrMismatchingCurrency},
+		{&Money{Units: 19, CurrencyCode: "USD"}, -1, limit, nil, ErrInvalidMultiplierProvided},
+		{&Money{Units: 19, Nanos: -1, CurrencyCode: "USD"}, 1.2, limit, nil, ErrInvalidValue},
+		{&Money{Units: 19, CurrencyCode: "USD"}, 1.2, nil, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := MulCapped(v.l, v.r, v.limit)
+		if err != v.err {
+			t.Errorf("MulCapped(%v, %v, %v): got error %v expected %v", v.l, v.r, v.limit, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("MulCapped(%v, %v, %v): got %v expected %v", v.l, v.r, v.limit, res, v.expected)
+		}
+	}
+}
+
+func TestMaxMinMoney(t *testing.T) {
+	max, min := MaxMoney("USD"), MinMoney("USD")
+	if !IsValid(max) || !IsValid(min) {
+		t.Fatalf("got invalid bounds %+v and %+v", *max, *min)
+	}
+	if max.CurrencyCode != "USD" || min.CurrencyCode != "USD" {
+		t.Errorf("got currencies %q and %q expected USD", max.CurrencyCode, min.CurrencyCode)
+	}
+	if !Equals(max.Inc(), max) || !Equals(min.Dec(), min) {
+		t.Errorf("bounds can be stepped past")
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		m := RandomMoney(rng, "USD")
+		if Compare(min, m) > 0 || Compare(m, max) > 0 {
+			t.Errorf("%+v is outside [%v, %v]", *m, min, max)
+		}
+	}
+}
+
+func TestMulToMinorUnits(t *testing.T) {
+	cases := []struct {
+		l        *Money
+		r        float64
+		mode     RoundingMode
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 19, Nanos: 990000000, CurrencyCode: "USD"}, 0.0825, HalfUp, &Money{Units: 1, Nanos: 650000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, CurrencyCode: "USD"}, 1.0000000001, Ceiling, &Money{Units: 1, Nanos: 10000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, CurrencyCode: "USD"}, 0.125, HalfEven, &Money{Nanos: 120000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -1, CurrencyCode: "USD"}, 0.125, HalfUp, &Money{Nanos: -130000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1500, CurrencyCode: "JPY"}, 0.1, Floor, &Money{Units: 150, CurrencyCode: "JPY"}, nil},
+		{&Money{Units: 1, CurrencyCode: "XYZ"}, 1.2, HalfUp, nil, ErrUnknownCurrency},
+		{&Money{Units: 1, CurrencyCode: "USD"}, -1, HalfUp, nil, ErrInvalidMultiplierProvided},
+		{&Money{Units: 1, CurrencyCode: "USD"}, 1.2, RoundingMode(-1), nil, ErrInvalidRoundingMode},
+		{&Money{Units: 1, Nanos: -1, CurrencyCode: "USD"}, 1.2, HalfUp, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := MulToMinorUnits(v.l, v.r, v.mode)
+		if err != v.err {
+			t.Errorf("MulToMinorUnits(%v, %v, %v): got error %v expected %v", v.l, v.r, v.mode, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("MulToMinorUnits(%v, %v, %v): got %v expected %v", v.l, v.r, v.mode, res, v.expected)
+		}
+	}
+}
+
+func TestMulToMinorUnitsAvoidsDoubleRounding(t *testing.T) {
+	l := &Money{Units: 1, CurrencyCode: "USD"}
+	r := 1.0000000001
+
+	product, err := Mul(l, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minor, err := ToMinorUnits(product, Ceiling)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if minor != 100 {
+		t.Fatalf("Mul then round got %d cents, expected the nano cut to give 100", minor)
+	}
+
+	res, err := MulToMinorUnits(l, r, Ceiling)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (&Money{Units: 1, Nanos: 10000000, CurrencyCode: "USD"}); *res != *expected {
+		t.Errorf("got %v expected %v", res, expected)
+	}
+}
+
+func TestDiv(t *testing.T) {
+	cases := []struct {
+		m        *Money
+		divisor  float64
+		mode     RoundingMode
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 10, CurrencyCode: "USD"}, 4, HalfUp, &Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, 3, HalfUp, &Money{Units: 3, Nanos: 333333333, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 10, CurrencyCode: "USD"}, 3, Ceiling, &Money{Units: 3, Nanos: 333333334, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 20, CurrencyCode: "USD"}, 3, Floor, &Money{Units: 6, Nanos: 666666666, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -10, CurrencyCode: "USD"}, 3, Floor, &Money{Units: -3, Nanos: -333333334, CurrencyCode: "USD"}, nil},
+		{&Money{Nanos: 5, CurrencyCode: "USD"}, 2, HalfEven, &Money{Nanos: 2, CurrencyCode: "USD"}, nil},
+		{&Money{Nanos: 5, CurrencyCode: "USD"}, 2, HalfUp, &Money{Nanos: 3, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, CurrencyCode: "USD"}, 0.1, HalfUp, &Money{Units: 10, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 19, Nanos: 990000000}, 1, HalfUp, &Money{Units: 19, Nanos: 990000000}, nil},
+		{&Money{Units: 1, CurrencyCode: "USD"}, 0, HalfUp, nil, ErrDivisionByZero},
+		{&Money{Units: 1, CurrencyCode: "USD"}, -2, HalfUp, nil, ErrInvalidMultiplierProvided},
+		{&Money{Units: 1, CurrencyCode: "USD"}, math.Inf(1), HalfUp, nil, ErrInvalidMultiplierProvided},
+		{&Money{Units: 1, CurrencyCode: "USD"}, math.NaN(), HalfUp, nil, ErrInvalidMultiplierProvided},
+		{&Money{Units: 1, CurrencyCode: "USD"}, 2, RoundingMode(-1), nil, ErrInvalidRoundingMode},
+		{&Money{Units: 1, Nanos: -1, CurrencyCode: "USD"}, 2, HalfUp, nil, ErrInvalidValue},
+		{&Money{Units: math.MaxInt64}, 0.5, HalfUp, nil, ErrOverflow},
+	}
+
+	for _, v := range cases {
+		res, err := Div(v.m, v.divisor, v.mode)
+		if err != v.err {
+			t.Errorf("Div(%v, %v, %v): got error %v expected %v", v.m, v.divisor, v.mode, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("Div(%v, %v, %v): got %v expected %v", v.m, v.divisor, v.mode, res, v.expected)
+		}
+	}
+}
+
+func TestIntegerAndFractionalPart(t *testing.T) {
+	cases := []struct {
+		input         *Money
+		integer, frac *Money
+	}{
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, &Money{Units: 19, CurrencyCode: "USD"}, &Money{Nanos: 130000000, CurrencyCode: "USD"}},
+		{&Money{Units: -2, Nanos: -500000000, CurrencyCode: "USD"}, &Money{Units: -2, CurrencyCode: "USD"}, &Money{Nanos: -500000000, CurrencyCode: "USD"}},
+		{&Money{Nanos: -1}, &Money{}, &Money{Nanos: -1}},
+		{&Money{Units: 7}, &Money{Units: 7}, &Money{}},
+		{&Money{Units: 1, Nanos: -250000000}, &Money{}, &Money{Nanos: 750000000}},
+		{nil, &Money{}, &Money{}},
+	}
+
+	for _, v := range cases {
+		integer, frac := IntegerPart(v.input), FractionalPart(v.input)
+		if *integer != *v.integer || *frac != *v.frac {
+			t.Errorf("%v: got %+v and %+v expected %+v and %+v", v.input, *integer, *frac, *v.integer, *v.frac)
+		}
+		if sum, err := Add(integer, frac); err != nil || !NumericEquals(sum, v.input) && v.input != nil {
+			t.Errorf("%v: parts add up to %v, %v", v.input, sum, err)
+		}
+	}
+}
+
+func TestClampNanos(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		expected *Money
+	}{
+		{&Money{Units: 5, Nanos: 1000000000, CurrencyCode: "USD"}, &Money{Units: 6, CurrencyCode: "USD"}},
+		{&Money{Units: -5, Nanos: -1000000000, CurrencyCode: "USD"}, &Money{Units: -6, CurrencyCode: "USD"}},
+		{&Money{Nanos: 1000000000}, &Money{Units: 1}},
+		{&Money{Nanos: -1000000000}, &Money{Units: -1}},
+		{&Money{Units: -1, Nanos: 1000000000}, &Money{}},
+		{&Money{Units: 19, Nanos: 130000000}, &Money{Units: 19, Nanos: 130000000}},
+		{&Money{Units: 1, Nanos: 1500000000}, &Money{Units: 1, Nanos: 1500000000}},
+		{&Money{Units: math.MaxInt64, Nanos: 1000000000}, &Money{Units: math.MaxInt64, Nanos: 1000000000}},
+	}
+
+	for _, v := range cases {
+		res := ClampNanos(v.input)
+		if *res != *v.expected {
+			t.Errorf("ClampNanos(%+v) got %+v expected %+v", *v.input, *res, *v.expected)
+		}
+		if res == v.input {
+			t.Errorf("ClampNanos(%+v) returned its input", *v.input)
+		}
+	}
+	if ClampNanos(nil) != nil {
+		t.Errorf("ClampNanos(nil) expected nil")
+	}
+}
+
 func BenchmarkDivideBy100(b *testing.B) {
 	// run the Fib function b.N times
 	for n := 0; n < b.N; n++ {
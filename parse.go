@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parse converts a decimal string such as "19.13" or "-0.5" to Money in the
+// given currency. At most nine fractional digits are accepted, and values
+// with more significant decimal places than MaxPrecision are rejected.
+func Parse(s, currencyCode string) (*Money, error) {
+	m, ok := parseTwoDecimals(s, currencyCode)
+	if !ok {
+		var err error
+		if m, err = parseDecimal(s, currencyCode); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkPrecision(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParseTagged parses strings of the form "CODE:amount", such as "USD:19.13",
+// as used in CLI flags and environment variables.
+func ParseTagged(s string) (*Money, error) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 || !isValidCurrencyCode(s[:i]) {
+		return nil, ErrInvalidCurrencyCode
+	}
+	return Parse(s[i+1:], s[:i])
+}
+
+// ParseRange parses a range of two decimal amounts separated by a hyphen,
+// such as "10.00-20.00", in the given currency. Either end may be negative:
+// the separator is the first hyphen that follows a digit or a space, so
+// "-20--10" and "-20 - -10" both mean -20 to -10. Spaces around the ends are
+// ignored. It returns ErrInvalidRange if there is no separator or the low end
+// is above the high end.
+func ParseRange(s, currency string) (low, high *Money, err error) {
+	sep := -1
+	for i := 1; i < len(s); i++ {
+		if prev := s[i-1]; s[i] == '-' && ('0' <= prev && prev <= '9' || prev == ' ') {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return nil, nil, ErrInvalidRange
+	}
+
+	if low, err = Parse(strings.TrimSpace(s[:sep]), currency); err != nil {
+		return nil, nil, err
+	}
+	if high, err = Parse(strings.TrimSpace(s[sep+1:]), currency); err != nil {
+		return nil, nil, err
+	}
+	if Compare(low, high) > 0 {
+		return nil, nil, ErrInvalidRange
+	}
+	return low, high, nil
+}
+
+// ParseFixedWidth reads amounts from fixed-width records of width bytes, as
+// found in legacy mainframe files. Each record holds a zero-padded integer
+// number of minor units of currency, e.g. "00001913" for USD 19.13, with an
+// optional sign. Spaces around the digits, line breaks between records and
+// white space after the last record are ignored.
+func ParseFixedWidth(r io.Reader, width int, currency string) ([]*Money, error) {
+	if width <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	mult, err := MultiplierFor(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	buf := make([]byte, width)
+	var res []*Money
+	for i := 0; ; i++ {
+		if err := skipLineBreaks(br); err != nil {
+			return nil, err
+		}
+		n, err := io.ReadFull(br, buf)
+		if err == io.EOF || (err == io.ErrUnexpectedEOF && strings.TrimSpace(string(buf[:n])) == "") {
+			return res, nil
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+
+		field := strings.TrimSpace(string(buf[:n]))
+		if field == "" {
+			blank, err := onlySpaceLeft(br)
+			if err != nil {
+				return nil, err
+			}
+			if blank {
+				return res, nil
+			}
+		}
+		if err == io.ErrUnexpectedEOF || !isDigits(strings.TrimLeft(field, "+-")) || strings.ContainsAny(field[1:], "+-") {
+			return nil, fmt.Errorf("record %d: %w", i, ErrInvalidDecimal)
+		}
+		amount, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, ErrInvalidDecimal)
+		}
+		res = append(res, fromInt(amount, mult, currency))
+	}
+}
+
+// onlySpaceLeft reports whether the rest of br is white space, consuming it.
+func onlySpaceLeft(br *bufio.Reader) (bool, error) {
+	rest, err := io.ReadAll(br)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(rest)) == "", nil
+}
+
+// skipLineBreaks consumes any line breaks at the start of br.
+func skipLineBreaks(br *bufio.Reader) error {
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if b != '\n' && b != '\r' {
+			return br.UnreadByte()
+		}
+	}
+}
+
+// parseDecimal is the general parser behind Parse.
+func parseDecimal(s, currencyCode string) (*Money, error) {
+	negative := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	vals := strings.Split(s, ".")
+	if len(vals) == 1 {
+		vals = append(vals, "")
+	} else if len(vals) != 2 || vals[1] == "" {
+		return nil, ErrInvalidDecimal
+	}
+	if !isDigits(vals[0]) || len(vals[1]) > 9 || (vals[1] != "" && !isDigits(vals[1])) {
+		return nil, ErrInvalidDecimal
+	}
+
+	units, err := strconv.ParseInt(vals[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidDecimal
+	}
+	nanos := convertNanos(vals[1])
+	if negative {
+		units, nanos = -units, -nanos
+	}
+
+	return &Money{
+		Units:        units,
+		Nanos:        nanos,
+		CurrencyCode: currencyCode,
+	}, nil
+}
+
+// parseTwoDecimals is a fast path for the common "dd.dd" form. It reads the
+// amount straight into minor units and reports false for any other input so
+// the caller can fall back to parseDecimal.
+func parseTwoDecimals(s, currencyCode string) (*Money, bool) {
+	negative := false
+	if len(s) > 0 && s[0] == '-' {
+		negative = true
+		s = s[1:]
+	}
+	// Up to 16 integer digits keep the minor units within int64.
+	n := len(s)
+	if n < 4 || n > 19 || s[n-3] != '.' {
+		return nil, false
+	}
+
+	var minor int64
+	for i := 0; i < n; i++ {
+		if i == n-3 {
+			continue
+		}
+		c := s[i]
+		if c < '0' || c > '9' {
+			return nil, false
+		}
+		minor = minor*10 + int64(c-'0')
+	}
+
+	units := minor / 100
+	nanos := int32(minor%100) * 10000000
+	if negative {
+		units, nanos = -units, -nanos
+	}
+
+	return &Money{
+		Units:        units,
+		Nanos:        nanos,
+		CurrencyCode: currencyCode,
+	}, true
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
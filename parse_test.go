@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected *Money
+		err      error
+	}{
+		{"19.13", &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, nil},
+		{"285", &Money{Units: 285, Nanos: 0, CurrencyCode: "USD"}, nil},
+		{"0.000055", &Money{Units: 0, Nanos: 55000, CurrencyCode: "USD"}, nil},
+		{"-1.5", &Money{Units: -1, Nanos: -500000000, CurrencyCode: "USD"}, nil},
+		{"-0.5", &Money{Units: 0, Nanos: -500000000, CurrencyCode: "USD"}, nil},
+		{"+2.25", &Money{Units: 2, Nanos: 250000000, CurrencyCode: "USD"}, nil},
+		{"", nil, ErrInvalidDecimal},
+		{"1.", nil, ErrInvalidDecimal},
+		{".5", nil, ErrInvalidDecimal},
+		{"1.2.3", nil, ErrInvalidDecimal},
+		{"1.0000000001", nil, ErrInvalidDecimal},
+		{"1.-5", nil, ErrInvalidDecimal},
+		{"abc", nil, ErrInvalidDecimal},
+	}
+
+	for _, v := range cases {
+		res, err := Parse(v.input, "USD")
+		if err != v.err {
+			t.Errorf("%q: got error %v expected %v", v.input, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%q: got %v expected %v", v.input, res, v.expected)
+		}
+	}
+}
+
+func TestParseTwoDecimalsMatchesGeneral(t *testing.T) {
+	inputs := []string{
+		"0.00", "0.01", "0.10", "19.13", "-19.13", "-0.07", "007.50",
+		"1234567890123456.99", "-1234567890123456.99",
+	}
+
+	for _, s := range inputs {
+		fast, ok := parseTwoDecimals(s, "EUR")
+		if !ok {
+			t.Errorf("%q: fast path not taken", s)
+			continue
+		}
+		general, err := parseDecimal(s, "EUR")
+		if err != nil {
+			t.Errorf("%q: general parser failed: %v", s, err)
+			continue
+		}
+		if *fast != *general {
+			t.Errorf("%q: fast %v general %v", s, fast, general)
+		}
+	}
+
+	for _, s := range []string{"1.5", "1.555", "+1.50", "1,50", "12345678901234567.00"} {
+		if _, ok := parseTwoDecimals(s, "EUR"); ok {
+			t.Errorf("%q: fast path should not be taken", s)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		_, _ = Parse("19.13", "USD")
+	}
+}
+
+func BenchmarkParseGeneral(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		_, _ = parseDecimal("19.13", "USD")
+	}
+}
+
+func TestParseTagged(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected *Money
+		err      error
+	}{
+		{"USD:19.13", &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, nil},
+		{"JPY:-500", &Money{Units: -500, CurrencyCode: "JPY"}, nil},
+		{"19.13", nil, ErrInvalidCurrencyCode},
+		{":19.13", nil, ErrInvalidCurrencyCode},
+		{"usd:19.13", nil, ErrInvalidCurrencyCode},
+		{"US:19.13", nil, ErrInvalidCurrencyCode},
+		{"USD1:19.13", nil, ErrInvalidCurrencyCode},
+		{"USD:", nil, ErrInvalidDecimal},
+		{"USD:19.13:1", nil, ErrInvalidDecimal},
+	}
+
+	for _, v := range cases {
+		res, err := ParseTagged(v.input)
+		if err != v.err {
+			t.Errorf("%q: got error %v expected %v", v.input, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%q: got %v expected %v", v.input, res, v.expected)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		input     string
+		low, high *Money
+		err       error
+	}{
+		{"10.00-20.00", &Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 20, CurrencyCode: "USD"}, nil},
+		{"10 - 20.5", &Money{Units: 10, CurrencyCode: "USD"}, &Money{Units: 20, Nanos: 500000000, CurrencyCode: "USD"}, nil},
+		{"-20--10", &Money{Units: -20, CurrencyCode: "USD"}, &Money{Units: -10, CurrencyCode: "USD"}, nil},
+		{"-20.50 - -10", &Money{Units: -20, Nanos: -500000000, CurrencyCode: "USD"}, &Money{Units: -10, CurrencyCode: "USD"}, nil},
+		{"-5-5", &Money{Units: -5, CurrencyCode: "USD"}, &Money{Units: 5, CurrencyCode: "USD"}, nil},
+		{"7-7", &Money{Units: 7, CurrencyCode: "USD"}, &Money{Units: 7, CurrencyCode: "USD"}, nil},
+		{"20.00-10.00", nil, nil, ErrInvalidRange},
+		{"-10--20", nil, nil, ErrInvalidRange},
+		{"10.00", nil, nil, ErrInvalidRange},
+		{"-10", nil, nil, ErrInvalidRange},
+		{"10-abc", nil, nil, ErrInvalidDecimal},
+		{"10.-20", nil, nil, ErrInvalidRange},
+	}
+
+	for _, v := range cases {
+		low, high, err := ParseRange(v.input, "USD")
+		if err != v.err {
+			t.Errorf("%q: got error %v expected %v", v.input, err, v.err)
+			continue
+		}
+		if v.low != nil && (*low != *v.low || *high != *v.high) {
+			t.Errorf("%q: got %v to %v expected %v to %v", v.input, low, high, v.low, v.high)
+		}
+	}
+}
+
+func TestParseFixedWidth(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		currency string
+		expected []*Money
+	}{
+		{"packed", "000019130000025000000001", "USD", []*Money{
+			{Units: 19, Nanos: 130000000, CurrencyCode: "USD"},
+			{Units: 2, Nanos: 500000000, CurrencyCode: "USD"},
+			{Nanos: 10000000, CurrencyCode: "USD"},
+		}},
+		{"lines", "00001913\n-0000250\r\n  000001\n\n  \n", "USD", []*Money{
+			{Units: 19, Nanos: 130000000, CurrencyCode: "USD"},
+			{Units: -2, Nanos: -500000000, CurrencyCode: "USD"},
+			{Nanos: 10000000, CurrencyCode: "USD"},
+		}},
+		{"no conversion", "00001500\n", "JPY", []*Money{{Units: 1500, CurrencyCode: "JPY"}}},
+		{"empty", "", "USD", nil},
+		{"blank", "        ", "USD", nil},
+		{"trailing blanks", "00001913        ", "USD", []*Money{{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}}},
+		{"trailing blank lines", "00001913\n        \n          \n", "USD", []*Money{{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}}},
+	}
+
+	for _, v := range cases {
+		res, err := ParseFixedWidth(strings.NewReader(v.input), 8, v.currency)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", v.name, err)
+			continue
+		}
+		if len(res) != len(v.expected) {
+			t.Errorf("%s: got %v expected %v", v.name, res, v.expected)
+			continue
+		}
+		for i := range res {
+			if *res[i] != *v.expected[i] {
+				t.Errorf("%s: record %d got %v expected %v", v.name, i, res[i], v.expected[i])
+			}
+		}
+	}
+}
+
+func TestParseFixedWidthErrors(t *testing.T) {
+	cases := []struct {
+		input    string
+		width    int
+		currency string
+		err      error
+	}{
+		{"00001913000", 8, "USD", ErrInvalidDecimal},
+		{"0000abcd", 8, "USD", ErrInvalidDecimal},
+		{"000-1913", 8, "USD", ErrInvalidDecimal},
+		{"        00001913", 8, "USD", ErrInvalidDecimal},
+		{"00001913", 0, "USD", ErrInvalidQuantity},
+		{"00001913", 8, "XYZ", ErrUnknownCurrency},
+	}
+
+	for _, v := range cases {
+		if _, err := ParseFixedWidth(strings.NewReader(v.input), v.width, v.currency); !errors.Is(err, v.err) {
+			t.Errorf("%q: got error %v expected %v", v.input, err, v.err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math"
+	"math/big"
+)
+
+// percentOf returns pct percent of m, rounded to the nearest nano with
+// halves away from zero.
+func percentOf(m *Money, pct float64) (*Money, error) {
+	r := toRat(m)
+	r.Mul(r, ratFromFloat(pct))
+	r.Quo(r, big.NewRat(100, 1))
+	return fromRat(r, m.GetCurrencyCode(), HalfUp)
+}
+
+// ApplyDiscount takes discountPercent percent off price. It returns both the
+// discounted price and the discount itself; the discount is rounded to the
+// nearest nano and the final price is derived from it, so that final plus
+// discountAmount is always exactly price.
+func ApplyDiscount(price *Money, discountPercent float64) (final *Money, discountAmount *Money, err error) {
+	if !(discountPercent >= 0 && discountPercent <= 100) {
+		return nil, nil, ErrInvalidPercentage
+	}
+	if price == nil || !IsValid(price) {
+		return nil, nil, ErrInvalidValue
+	}
+
+	if discountAmount, err = percentOf(price, discountPercent); err != nil {
+		return nil, nil, err
+	}
+	remaining := new(big.Int).Sub(toNanos(price), toNanos(discountAmount))
+	if final, err = fromNanos(remaining, price.GetCurrencyCode()); err != nil {
+		return nil, nil, err
+	}
+	return final, discountAmount, nil
+}
+
+// AddPercentage returns m increased by pct percent, e.g. a price plus tax.
+// The added amount is rounded to the nearest nano with halves away from zero.
+func AddPercentage(m *Money, pct float64) (*Money, error) {
+	if !(pct >= 0) || math.IsInf(pct, 0) {
+		return nil, ErrInvalidPercentage
+	}
+	if m == nil || !IsValid(m) {
+		return nil, ErrInvalidValue
+	}
+	extra, err := percentOf(m, pct)
+	if err != nil {
+		return nil, err
+	}
+	return Add(m, extra)
+}
+
+// CheckoutTotal returns subtotal plus shipping, plus taxPercent percent of
+// that sum. A nil shipping means free shipping.
+func CheckoutTotal(subtotal, shipping *Money, taxPercent float64) (*Money, error) {
+	if subtotal == nil {
+		return nil, ErrInvalidValue
+	}
+	beforeTax, err := Add(subtotal, shipping)
+	if err != nil {
+		return nil, err
+	}
+	return AddPercentage(beforeTax, taxPercent)
+}
+
+// RemovePercentage undoes AddPercentage: it returns the amount that m is pct
+// percent more than, e.g. the net price in a gross price including tax. The
+// result is rounded to the nearest nano with halves away from zero.
+func RemovePercentage(m *Money, pct float64) (*Money, error) {
+	if !(pct >= 0) || math.IsInf(pct, 0) {
+		return nil, ErrInvalidPercentage
+	}
+	if m == nil || !IsValid(m) {
+		return nil, ErrInvalidValue
+	}
+	factor := ratFromFloat(pct)
+	factor.Quo(factor, big.NewRat(100, 1))
+	factor.Add(factor, big.NewRat(1, 1))
+	r := toRat(m)
+	return fromRat(r.Quo(r, factor), m.CurrencyCode, HalfUp)
+}
+
+// ExtractTaxTotal returns the tax included in grossPrices at ratePercent:
+// for each price the difference between it and its net price as given by
+// RemovePercentage, summed up. Each tax portion is rounded like the net
+// price, so it adds up with the net price to the gross price exactly. Nil
+// prices are skipped.
+func ExtractTaxTotal(grossPrices []*Money, ratePercent float64) (*Money, error) {
+	if !(ratePercent >= 0) || math.IsInf(ratePercent, 0) {
+		return nil, ErrInvalidPercentage
+	}
+	_, code, err := sumNanos(grossPrices)
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	for _, gross := range grossPrices {
+		if gross == nil {
+			continue
+		}
+		net, err := RemovePercentage(gross, ratePercent)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, toNanos(gross))
+		total.Sub(total, toNanos(net))
+	}
+	return fromNanos(total, code)
+}
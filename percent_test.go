@@ -0,0 +1,164 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyDiscount(t *testing.T) {
+	cases := []struct {
+		price    *Money
+		percent  float64
+		final    *Money
+		discount *Money
+		err      error
+	}{
+		{
+			&Money{Units: 100, CurrencyCode: "USD"}, 15,
+			&Money{Units: 85, CurrencyCode: "USD"}, &Money{Units: 15, CurrencyCode: "USD"}, nil,
+		},
+		{
+			&Money{Units: 19, Nanos: 990000000, CurrencyCode: "USD"}, 15,
+			&Money{Units: 16, Nanos: 991500000, CurrencyCode: "USD"}, &Money{Units: 2, Nanos: 998500000, CurrencyCode: "USD"}, nil,
+		},
+		{
+			&Money{Units: 0, Nanos: 10, CurrencyCode: "USD"}, 33.33,
+			&Money{Units: 0, Nanos: 7, CurrencyCode: "USD"}, &Money{Units: 0, Nanos: 3, CurrencyCode: "USD"}, nil,
+		},
+		{
+			&Money{Units: 42, Nanos: 500000000, CurrencyCode: "USD"}, 100,
+			&Money{CurrencyCode: "USD"}, &Money{Units: 42, Nanos: 500000000, CurrencyCode: "USD"}, nil,
+		},
+		{
+			&Money{Units: 42, CurrencyCode: "USD"}, 0,
+			&Money{Units: 42, CurrencyCode: "USD"}, &Money{CurrencyCode: "USD"}, nil,
+		},
+		{&Money{Units: 42}, 100.5, nil, nil, ErrInvalidPercentage},
+		{&Money{Units: 42}, -1, nil, nil, ErrInvalidPercentage},
+		{&Money{Units: 42}, math.NaN(), nil, nil, ErrInvalidPercentage},
+		{&Money{Units: 42, Nanos: -1}, 15, nil, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		final, discount, err := ApplyDiscount(v.price, v.percent)
+		if err != v.err {
+			t.Errorf("%v - %v%%: got error %v expected %v", v.price, v.percent, err, v.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !Equals(final, v.final) || !Equals(discount, v.discount) {
+			t.Errorf("%v - %v%%: got %v, %v expected %v, %v", v.price, v.percent, final, discount, v.final, v.discount)
+		}
+	}
+}
+
+func TestAddPercentage(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		percent  float64
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 100, CurrencyCode: "USD"}, 20, &Money{Units: 120, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 0, Nanos: 10, CurrencyCode: "USD"}, 15, &Money{Units: 0, Nanos: 12, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -10, CurrencyCode: "USD"}, 7.5, &Money{Units: -10, Nanos: -750000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 42}, -1, nil, ErrInvalidPercentage},
+		{&Money{Units: 42}, math.Inf(1), nil, ErrInvalidPercentage},
+		{&Money{Units: 42}, math.NaN(), nil, ErrInvalidPercentage},
+		{nil, 10, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := AddPercentage(v.input, v.percent)
+		if err != v.err {
+			t.Errorf("%v + %v%%: got error %v expected %v", v.input, v.percent, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v + %v%%: got %v expected %v", v.input, v.percent, res, v.expected)
+		}
+	}
+}
+
+func TestCheckoutTotal(t *testing.T) {
+	subtotal := &Money{Units: 89, Nanos: 970000000, CurrencyCode: "USD"}
+	cases := []struct {
+		subtotal, shipping *Money
+		tax                float64
+		expected           *Money
+		err                error
+	}{
+		{subtotal, &Money{Units: 5, Nanos: 990000000, CurrencyCode: "USD"}, 8.25, &Money{Units: 103, Nanos: 876700000, CurrencyCode: "USD"}, nil},
+		{subtotal, nil, 0, subtotal, nil},
+		{subtotal, &Money{Units: 5, CurrencyCode: "EUR"}, 8.25, nil, ErrMismatchingCurrency},
+		{subtotal, &Money{Units: 5, CurrencyCode: "USD"}, -1, nil, ErrInvalidPercentage},
+		{subtotal, &Money{Units: 5, Nanos: -1, CurrencyCode: "USD"}, 8.25, nil, ErrInvalidValue},
+		{nil, &Money{Units: 5, CurrencyCode: "USD"}, 8.25, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := CheckoutTotal(v.subtotal, v.shipping, v.tax)
+		if err != v.err {
+			t.Errorf("%v + %v at %v%%: got error %v expected %v", v.subtotal, v.shipping, v.tax, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v + %v at %v%%: got %v expected %v", v.subtotal, v.shipping, v.tax, res, v.expected)
+		}
+	}
+}
+
+func TestRemovePercentage(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		percent  float64
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 120, CurrencyCode: "EUR"}, 20, &Money{Units: 100, CurrencyCode: "EUR"}, nil},
+		{&Money{Units: 10, CurrencyCode: "EUR"}, 20, &Money{Units: 8, Nanos: 333333333, CurrencyCode: "EUR"}, nil},
+		{&Money{Units: -12, CurrencyCode: "EUR"}, 20, &Money{Units: -10, CurrencyCode: "EUR"}, nil},
+		{&Money{Units: 42}, 0, &Money{Units: 42}, nil},
+		{&Money{Units: 42}, -1, nil, ErrInvalidPercentage},
+		{&Money{Units: 42}, math.NaN(), nil, ErrInvalidPercentage},
+		{nil, 20, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := RemovePercentage(v.input, v.percent)
+		if err != v.err {
+			t.Errorf("%v - %v%%: got error %v expected %v", v.input, v.percent, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v - %v%%: got %v expected %v", v.input, v.percent, res, v.expected)
+		}
+	}
+}
+
+func TestExtractTaxTotal(t *testing.T) {
+	prices := []*Money{
+		{Units: 120, CurrencyCode: "EUR"},
+		{Units: 10, CurrencyCode: "EUR"},
+		nil,
+		{Units: 5, Nanos: 990000000, CurrencyCode: "EUR"},
+	}
+	res, err := ExtractTaxTotal(prices, 20)
+	expected := &Money{Units: 22, Nanos: 665000000, CurrencyCode: "EUR"}
+	if err != nil || *res != *expected {
+		t.Errorf("got %v, %v expected %v", res, err, expected)
+	}
+
+	if res, err := ExtractTaxTotal(nil, 20); err != nil || *res != (Money{}) {
+		t.Errorf("no prices: got %v, %v expected 0", res, err)
+	}
+	if _, err := ExtractTaxTotal(nil, -5); err != ErrInvalidPercentage {
+		t.Errorf("got error %v expected %v", err, ErrInvalidPercentage)
+	}
+	mixed := append(prices, &Money{Units: 1, CurrencyCode: "USD"})
+	if _, err := ExtractTaxTotal(mixed, 20); err != ErrMismatchingCurrency {
+		t.Errorf("got error %v expected %v", err, ErrMismatchingCurrency)
+	}
+}
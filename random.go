@@ -0,0 +1,20 @@
+package main
+
+import "math/rand"
+
+// RandomMoney returns a valid Money in currency drawn from rng, for use in
+// property-based tests. Units are uniform over the whole int64 range and
+// nanos uniform over their range, with the sign of the nanos following the
+// units; a zero units value gets nanos of either sign.
+func RandomMoney(rng *rand.Rand, currency string) *Money {
+	units := int64(rng.Uint64())
+	nanos := rng.Int31n(nanosMax + 1)
+	if units < 0 || (units == 0 && rng.Intn(2) == 0) {
+		nanos = -nanos
+	}
+	return &Money{
+		Units:        units,
+		Nanos:        nanos,
+		CurrencyCode: currency,
+	}
+}
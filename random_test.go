@@ -0,0 +1,26 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRandomMoneyIsValid(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var negative, positive int
+	for i := 0; i < 10000; i++ {
+		m := RandomMoney(rng, "USD")
+		if !IsValid(m) || m.CurrencyCode != "USD" {
+			t.Fatalf("iteration %d: got invalid value %+v", i, *m)
+		}
+		switch {
+		case m.Units < 0:
+			negative++
+		case m.Units > 0:
+			positive++
+		}
+	}
+	if negative == 0 || positive == 0 {
+		t.Errorf("got %d negative and %d positive values expected both signs", negative, positive)
+	}
+}
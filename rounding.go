@@ -0,0 +1,128 @@
+package main
+
+import (
+	"math/big"
+	"strconv"
+)
+
+// RoundingMode selects how a value that falls between two representable
+// amounts is rounded.
+type RoundingMode int
+
+const (
+	// HalfUp rounds to the nearest value, halves away from zero.
+	HalfUp RoundingMode = iota
+	// HalfDown rounds to the nearest value, halves towards zero.
+	HalfDown
+	// HalfEven rounds to the nearest value, halves to the even neighbour
+	// (banker's rounding).
+	HalfEven
+	// Floor rounds towards negative infinity.
+	Floor
+	// Ceiling rounds towards positive infinity.
+	Ceiling
+	// AwayFromZero rounds any remainder away from zero.
+	AwayFromZero
+	// TowardZero drops any remainder, truncating towards zero.
+	TowardZero
+)
+
+var roundingModeNames = map[RoundingMode]string{
+	HalfUp:       "HalfUp",
+	HalfDown:     "HalfDown",
+	HalfEven:     "HalfEven",
+	Floor:        "Floor",
+	Ceiling:      "Ceiling",
+	AwayFromZero: "AwayFromZero",
+	TowardZero:   "TowardZero",
+}
+
+func (m RoundingMode) String() string {
+	if name, ok := roundingModeNames[m]; ok {
+		return name
+	}
+	return "RoundingMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// valid reports whether m is one of the declared rounding modes.
+func (m RoundingMode) valid() bool {
+	_, ok := roundingModeNames[m]
+	return ok
+}
+
+// roundQuo divides num by den and rounds the quotient according to mode.
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return q
+	}
+
+	negative := (num.Sign() < 0) != (den.Sign() < 0)
+	half := rem.Lsh(rem.Abs(rem), 1).CmpAbs(den)
+
+	// awayFromZero is true when the truncated quotient has to move one step
+	// away from zero.
+	var awayFromZero bool
+	switch mode {
+	case HalfUp:
+		awayFromZero = half >= 0
+	case HalfDown:
+		awayFromZero = half > 0
+	case HalfEven:
+		awayFromZero = half > 0 || (half == 0 && q.Bit(0) == 1)
+	case Floor:
+		awayFromZero = negative
+	case Ceiling:
+		awayFromZero = !negative
+	case AwayFromZero:
+		awayFromZero = true
+	}
+
+	if awayFromZero {
+		if negative {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// RoundAsymmetric rounds m to places decimal places, using posMode for
+// positive amounts and negMode for negative ones, as some tax rules require.
+// For example, AwayFromZero for both rounds 1.231 up to 1.24 and -1.231 down
+// to -1.24.
+func RoundAsymmetric(m *Money, places int, posMode, negMode RoundingMode) (*Money, error) {
+	if !posMode.valid() || !negMode.valid() {
+		return nil, ErrInvalidRoundingMode
+	}
+	if places < 0 || places > 9 {
+		return nil, ErrInvalidPlaces
+	}
+	if !IsValid(m) {
+		return nil, ErrInvalidValue
+	}
+	n := toNanos(m)
+	mode := posMode
+	if n.Sign() < 0 {
+		mode = negMode
+	}
+	step := big.NewInt(pow10(9 - places))
+	n = roundQuo(n, step, mode)
+	return fromNanos(n.Mul(n, step), m.GetCurrencyCode())
+}
+
+// RoundToUnitMultiple rounds m to the nearest multiple of multiple whole
+// units, e.g. to the nearest $10 for a multiple of 10, with halves rounded
+// away from zero. The result has no nanos.
+func RoundToUnitMultiple(m *Money, multiple int64) (*Money, error) {
+	if multiple <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if !IsValid(m) {
+		return nil, ErrInvalidValue
+	}
+	step := new(big.Int).Mul(big.NewInt(multiple), nanosPerUnit)
+	n := roundQuo(toNanos(m), step, HalfUp)
+	return fromNanos(n.Mul(n, step), m.GetCurrencyCode())
+}
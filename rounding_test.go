@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestRoundQuoDirectedModes(t *testing.T) {
+	cases := []struct {
+		num      int64
+		mode     RoundingMode
+		expected int64
+	}{
+		{21, AwayFromZero, 3},
+		{-21, AwayFromZero, -3},
+		{20, AwayFromZero, 2},
+		{29, TowardZero, 2},
+		{-29, TowardZero, -2},
+	}
+
+	for _, v := range cases {
+		if res := roundQuo(big.NewInt(v.num), big.NewInt(10), v.mode); res.Int64() != v.expected {
+			t.Errorf("%d / 10 %v: got %v expected %d", v.num, v.mode, res, v.expected)
+		}
+	}
+}
+
+func TestRoundAsymmetric(t *testing.T) {
+	cases := []struct {
+		input            *Money
+		places           int
+		posMode, negMode RoundingMode
+		expected         *Money
+		err              error
+	}{
+		{&Money{Units: 1, Nanos: 231000000, CurrencyCode: "USD"}, 2, Ceiling, Floor, &Money{Units: 1, Nanos: 240000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -1, Nanos: -231000000, CurrencyCode: "USD"}, 2, Ceiling, Floor, &Money{Units: -1, Nanos: -240000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1, Nanos: 239000000, CurrencyCode: "USD"}, 2, TowardZero, AwayFromZero, &Money{Units: 1, Nanos: 230000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -1, Nanos: -231000000, CurrencyCode: "USD"}, 2, TowardZero, AwayFromZero, &Money{Units: -1, Nanos: -240000000, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, 0, HalfUp, HalfDown, &Money{Units: 3, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -2, Nanos: -500000000, CurrencyCode: "USD"}, 0, HalfUp, HalfDown, &Money{Units: -2, CurrencyCode: "USD"}, nil},
+		{&Money{Nanos: 1, CurrencyCode: "USD"}, 9, Floor, Floor, &Money{Nanos: 1, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 1}, 10, HalfUp, HalfUp, nil, ErrInvalidPlaces},
+		{&Money{Units: 1}, -1, HalfUp, HalfUp, nil, ErrInvalidPlaces},
+		{&Money{Units: 1}, 2, RoundingMode(-1), HalfUp, nil, ErrInvalidRoundingMode},
+		{&Money{Units: 1, Nanos: -1}, 2, HalfUp, HalfUp, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := RoundAsymmetric(v.input, v.places, v.posMode, v.negMode)
+		if err != v.err {
+			t.Errorf("%v to %d places: got error %v expected %v", v.input, v.places, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v to %d places: got %v expected %v", v.input, v.places, res, v.expected)
+		}
+	}
+}
+
+func TestRoundToUnitMultiple(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		multiple int64
+		expected *Money
+		err      error
+	}{
+		{&Money{Units: 47, CurrencyCode: "USD"}, 10, &Money{Units: 50, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 44, Nanos: 990000000, CurrencyCode: "USD"}, 10, &Money{Units: 40, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 45, CurrencyCode: "USD"}, 10, &Money{Units: 50, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -45, CurrencyCode: "USD"}, 10, &Money{Units: -50, CurrencyCode: "USD"}, nil},
+		{&Money{Units: -44, Nanos: -500000000, CurrencyCode: "USD"}, 10, &Money{Units: -40, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 149, CurrencyCode: "USD"}, 100, &Money{Units: 100, CurrencyCode: "USD"}, nil},
+		{&Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, 1, &Money{Units: 3, CurrencyCode: "USD"}, nil},
+		{&Money{Units: math.MaxInt64}, 10, nil, ErrOverflow},
+		{&Money{Units: 47}, 0, nil, ErrInvalidQuantity},
+		{&Money{Units: 47}, -10, nil, ErrInvalidQuantity},
+		{&Money{Units: 47, Nanos: -1}, 10, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := RoundToUnitMultiple(v.input, v.multiple)
+		if err != v.err {
+			t.Errorf("%v to a multiple of %d: got error %v expected %v", v.input, v.multiple, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%v to a multiple of %d: got %v expected %v", v.input, v.multiple, res, v.expected)
+		}
+	}
+}
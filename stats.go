@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math/big"
+	"sort"
+)
+
+// Average returns the mean of the non-nil items, rounded to the nearest nano
+// with halves rounded away from zero. The items must share a currency.
+func Average(items []*Money) (*Money, error) {
+	total, code, err := sumNanos(items)
+	if err != nil {
+		return nil, err
+	}
+	var count int64
+	for _, m := range items {
+		if m != nil {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil, ErrEmptySlice
+	}
+	return fromNanos(roundQuo(total, big.NewInt(count), HalfUp), code)
+}
+
+// Median returns the middle value of the non-nil items, which must share a
+// currency. For an even count it is the average of the two middle values,
+// rounded to the nearest nano with halves away from zero.
+func Median(items []*Money) (*Money, error) {
+	_, code, err := sumNanos(items)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*big.Int, 0, len(items))
+	for _, m := range items {
+		if m != nil {
+			values = append(values, toNanos(m))
+		}
+	}
+	if len(values) == 0 {
+		return nil, ErrEmptySlice
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return fromNanos(values[mid], code)
+	}
+	sum := new(big.Int).Add(values[mid-1], values[mid])
+	return fromNanos(roundQuo(sum, big.NewInt(2), HalfUp), code)
+}
+
+// SumDrift measures how much rounding each item separately drifts from
+// rounding the total once: it returns the sum of Mul(item, rate) over the
+// items minus Mul of their sum. A zero drift means rounding per item and
+// rounding the total agree. Nil items are skipped.
+func SumDrift(items []*Money, rate float64) (*Money, error) {
+	sum, err := Sum(items)
+	if err != nil {
+		return nil, err
+	}
+	whole, err := Mul(sum, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := new(big.Int)
+	for _, m := range items {
+		if m == nil {
+			continue
+		}
+		p, err := Mul(m, rate)
+		if err != nil {
+			return nil, err
+		}
+		drift.Add(drift, toNanos(p))
+	}
+	return fromNanos(drift.Sub(drift, toNanos(whole)), sum.CurrencyCode)
+}
+
+// Histogram counts the non-nil items per bucket of width bucketSize, where
+// an amount falls into bucket FloorDiv(amount, bucketSize): bucket 0 holds
+// [0, bucketSize), bucket -1 holds [-bucketSize, 0) and so on.
+func Histogram(items []*Money, bucketSize *Money) (map[int64]int, error) {
+	if !IsPositive(bucketSize) {
+		return nil, ErrInvalidValue
+	}
+	res := make(map[int64]int)
+	for _, m := range items {
+		if m == nil {
+			continue
+		}
+		bucket, err := FloorDiv(m, bucketSize)
+		if err != nil {
+			return nil, err
+		}
+		res[bucket]++
+	}
+	return res, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import "testing"
+
+func TestAverage(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	cases := []struct {
+		name     string
+		items    []*Money
+		expected *Money
+		err      error
+	}{
+		{"exact", []*Money{usd(1, 0), usd(2, 0), usd(3, 0)}, usd(2, 0), nil},
+		{"inexact", []*Money{usd(0, 100000000), usd(0, 100000000), usd(0, 110000000)}, usd(0, 103333333), nil},
+		{"rounds half up", []*Money{usd(0, 1), usd(0, 2)}, usd(0, 2), nil},
+		{"negative", []*Money{usd(-1, 0), usd(0, -500000000)}, usd(0, -750000000), nil},
+		{"skips nil", []*Money{usd(1, 0), nil, usd(2, 0)}, usd(1, 500000000), nil},
+		{"empty", nil, nil, ErrEmptySlice},
+		{"all nil", []*Money{nil}, nil, ErrEmptySlice},
+		{"mismatched", []*Money{usd(1, 0), {Units: 1, CurrencyCode: "EUR"}}, nil, ErrMismatchingCurrency},
+		{"invalid", []*Money{usd(1, -1)}, nil, ErrInvalidValue},
+	}
+
+	for _, v := range cases {
+		res, err := Average(v.items)
+		if err != v.err {
+			t.Errorf("%s: got error %v expected %v", v.name, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%s: got %v expected %v", v.name, res, v.expected)
+		}
+	}
+}
+
+func TestSumDrift(t *testing.T) {
+	cases := []struct {
+		name     string
+		items    []*Money
+		rate     float64
+		expected *Money
+		err      error
+	}{
+		{"drift", []*Money{{Nanos: 1, CurrencyCode: "USD"}, {Nanos: 1, CurrencyCode: "USD"}, {Nanos: 1, CurrencyCode: "USD"}}, 0.5, &Money{Nanos: -1, CurrencyCode: "USD"}, nil},
+		{"no drift", []*Money{{Units: 19, CurrencyCode: "USD"}, nil, {Units: 1, CurrencyCode: "USD"}}, 1.2, &Money{CurrencyCode: "USD"}, nil},
+		{"empty", nil, 1.2, &Money{}, nil},
+		{"mismatched", []*Money{{Units: 1, CurrencyCode: "USD"}, {Units: 1, CurrencyCode: "EUR"}}, 1.2, nil, ErrMismatchingCurrency},
+		{"negative rate", []*Money{{Units: 1, CurrencyCode: "USD"}}, -1, nil, ErrInvalidMultiplierProvided},
+	}
+
+	for _, v := range cases {
+		res, err := SumDrift(v.items, v.rate)
+		if err != v.err {
+			t.Errorf("%s: got error %v expected %v", v.name, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%s: got %v expected %v", v.name, res, v.expected)
+		}
+	}
+}
+
+func TestMedian(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	cases := []struct {
+		name     string
+		items    []*Money
+		expected *Money
+		err      error
+	}{
+		{"odd", []*Money{usd(5, 0), usd(1, 0), usd(3, 0)}, usd(3, 0), nil},
+		{"even", []*Money{usd(4, 0), usd(1, 0), usd(2, 0), usd(10, 0)}, usd(3, 0), nil},
+		{"even rounds half up", []*Money{usd(0, 1), usd(0, 2)}, usd(0, 2), nil},
+		{"even negative", []*Money{usd(0, -1), usd(0, -2)}, usd(0, -2), nil},
+		{"single", []*Money{usd(7, 500000000)}, usd(7, 500000000), nil},
+		{"skips nil", []*Money{nil, usd(2, 0), usd(-1, 0), nil, usd(9, 0)}, usd(2, 0), nil},
+		{"empty", nil, nil, ErrEmptySlice},
+		{"mismatched", []*Money{usd(1, 0), {Units: 2, CurrencyCode: "EUR"}}, nil, ErrMismatchingCurrency},
+	}
+
+	for _, v := range cases {
+		res, err := Median(v.items)
+		if err != v.err {
+			t.Errorf("%s: got error %v expected %v", v.name, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("%s: got %v expected %v", v.name, res, v.expected)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	usd := func(units int64, nanos int32) *Money {
+		return &Money{Units: units, Nanos: nanos, CurrencyCode: "USD"}
+	}
+	items := []*Money{usd(0, 0), usd(9, 990000000), usd(10, 0), usd(15, 0), nil, usd(0, -10000000), usd(-10, 0), usd(-10, -1), usd(123, 0)}
+
+	res, err := Histogram(items, usd(10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[int64]int{0: 2, 1: 2, -1: 2, -2: 1, 12: 1}
+	if len(res) != len(expected) {
+		t.Errorf("got %v expected %v", res, expected)
+	}
+	for k, c := range expected {
+		if res[k] != c {
+			t.Errorf("bucket %d: got %d expected %d", k, res[k], c)
+		}
+	}
+
+	for _, size := range []*Money{usd(0, 0), usd(-10, 0), nil} {
+		if _, err := Histogram(items, size); err != ErrInvalidValue {
+			t.Errorf("bucket size %v: got error %v expected %v", size, err, ErrInvalidValue)
+		}
+	}
+	if _, err := Histogram([]*Money{{Units: 1, CurrencyCode: "EUR"}}, usd(10, 0)); err != ErrMismatchingCurrency {
+		t.Errorf("got error %v expected %v", err, ErrMismatchingCurrency)
+	}
+}
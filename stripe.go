@@ -0,0 +1,29 @@
+package main
+
+import "strings"
+
+// ToStripeAmount converts m to the representation used by the Stripe API: an
+// integer number of the currency's minor units and a lower case currency
+// code, e.g. 1913 and "usd" for USD 19.13. Nanos that don't make up a whole
+// minor unit are rounded to the nearest one, with halves to even; check
+// IsSettleable first to refuse such amounts instead. It returns ErrOverflow
+// if the minor units don't fit in an int64.
+func ToStripeAmount(m *Money) (int64, string, error) {
+	amount, err := ToMinorUnits(m, HalfEven)
+	if err != nil {
+		return 0, "", err
+	}
+	return amount, strings.ToLower(m.GetCurrencyCode()), nil
+}
+
+// FromStripeAmount converts a Stripe amount in minor units of currency back
+// to Money. The currency code is accepted in either case and the result
+// carries it in upper case. The conversion is exact and can't overflow.
+func FromStripeAmount(amount int64, currency string) (*Money, error) {
+	code := strings.ToUpper(currency)
+	mult, err := MultiplierFor(code)
+	if err != nil {
+		return nil, err
+	}
+	return fromInt(amount, mult, code), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToStripeAmount(t *testing.T) {
+	cases := []struct {
+		input    *Money
+		amount   int64
+		currency string
+		err      error
+	}{
+		{&Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, 1913, "usd", nil},
+		{&Money{Units: -2, Nanos: -500000000, CurrencyCode: "EUR"}, -250, "eur", nil},
+		{&Money{Units: 19, Nanos: 135000000, CurrencyCode: "USD"}, 1914, "usd", nil},
+		{&Money{Units: 19, Nanos: 125000000, CurrencyCode: "USD"}, 1912, "usd", nil},
+		{&Money{Units: 1500, CurrencyCode: "JPY"}, 1500, "jpy", nil},
+		{&Money{Units: math.MaxInt64, CurrencyCode: "USD"}, 0, "", ErrOverflow},
+		{&Money{Units: 1, CurrencyCode: "XYZ"}, 0, "", ErrUnknownCurrency},
+	}
+
+	for _, v := range cases {
+		amount, currency, err := ToStripeAmount(v.input)
+		if err != v.err || amount != v.amount || currency != v.currency {
+			t.Errorf("ToStripeAmount(%v): got %d, %q, %v expected %d, %q, %v", v.input, amount, currency, err, v.amount, v.currency, v.err)
+		}
+	}
+}
+
+func TestFromStripeAmount(t *testing.T) {
+	cases := []struct {
+		amount   int64
+		currency string
+		expected *Money
+		err      error
+	}{
+		{1913, "usd", &Money{Units: 19, Nanos: 130000000, CurrencyCode: "USD"}, nil},
+		{-250, "EUR", &Money{Units: -2, Nanos: -500000000, CurrencyCode: "EUR"}, nil},
+		{1500, "jpy", &Money{Units: 1500, CurrencyCode: "JPY"}, nil},
+		{1, "xyz", nil, ErrUnknownCurrency},
+	}
+
+	for _, v := range cases {
+		res, err := FromStripeAmount(v.amount, v.currency)
+		if err != v.err {
+			t.Errorf("FromStripeAmount(%d, %q): got error %v expected %v", v.amount, v.currency, err, v.err)
+			continue
+		}
+		if v.expected != nil && *res != *v.expected {
+			t.Errorf("FromStripeAmount(%d, %q): got %v expected %v", v.amount, v.currency, res, v.expected)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import "math/big"
+
+// residuePerNano is the resolution of TrackedMoney.RoundingError: a
+// billionth of a nano.
+var residuePerNano = big.NewInt(nanosMod)
+
+// TrackedMoney is a Money that remembers whether it has been rounded along
+// the way, for audit trails that need to know when a value is approximate.
+type TrackedMoney struct {
+	Money
+	// Rounded is set once an operation had to discard precision.
+	Rounded bool
+	// RoundingError accumulates what was discarded, exact result minus
+	// rounded result, in billionths of a nano.
+	RoundingError int64
+}
+
+// Track returns m as a TrackedMoney that hasn't been rounded. A nil m is
+// tracked as zero.
+func Track(m *Money) *TrackedMoney {
+	return &TrackedMoney{Money: Money{Units: m.GetUnits(), Nanos: m.GetNanos(), CurrencyCode: m.GetCurrencyCode()}}
+}
+
+// Plain returns a copy of the value of t without the tracking information.
+func (t *TrackedMoney) Plain() *Money {
+	return &Money{Units: t.Units, Nanos: t.Nanos, CurrencyCode: t.CurrencyCode}
+}
+
+// Mul multiplies t by r like Mul does and records any precision the product
+// loses at nano resolution.
+func (t *TrackedMoney) Mul(r float64) (*TrackedMoney, error) {
+	res, err := Mul(&t.Money, r)
+	if err != nil {
+		return nil, err
+	}
+	exact := toRat(&t.Money)
+	exact.Mul(exact, ratFromFloat(r))
+	return t.track(res, exact), nil
+}
+
+// Div divides t by divisor like Div does, rounding according to mode, and
+// records any precision the quotient loses at nano resolution.
+func (t *TrackedMoney) Div(divisor float64, mode RoundingMode) (*TrackedMoney, error) {
+	res, err := Div(&t.Money, divisor, mode)
+	if err != nil {
+		return nil, err
+	}
+	exact := toRat(&t.Money)
+	exact.Quo(exact, ratFromFloat(divisor))
+	return t.track(res, exact), nil
+}
+
+// track returns res as the result of an operation on t whose exact result,
+// in units, was exact.
+func (t *TrackedMoney) track(res *Money, exact *big.Rat) *TrackedMoney {
+	residue := exact.Sub(exact, toRat(res))
+	residue.Mul(residue, new(big.Rat).SetInt(new(big.Int).Mul(nanosPerUnit, residuePerNano)))
+	discarded := roundQuo(residue.Num(), residue.Denom(), HalfEven)
+
+	return &TrackedMoney{
+		Money:         *res,
+		Rounded:       t.Rounded || residue.Sign() != 0,
+		RoundingError: t.RoundingError + discarded.Int64(),
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import "testing"
+
+func TestTrackedMoneyMul(t *testing.T) {
+	cases := []struct {
+		input         *Money
+		r             float64
+		expected      Money
+		rounded       bool
+		roundingError int64
+	}{
+		{&Money{Units: 19, CurrencyCode: "USD"}, 1.2, Money{Units: 22, Nanos: 800000000, CurrencyCode: "USD"}, false, 0},
+		{&Money{Units: 100, CurrencyCode: "USD"}, 0.07, Money{Units: 7, CurrencyCode: "USD"}, false, 0},
+		{&Money{Nanos: 1, CurrencyCode: "USD"}, 0.5, Money{CurrencyCode: "USD"}, true, 500000000},
+		{&Money{Nanos: 3, CurrencyCode: "USD"}, 0.25, Money{CurrencyCode: "USD"}, true, 750000000},
+	}
+
+	for _, v := range cases {
+		res, err := Track(v.input).Mul(v.r)
+		if err != nil {
+			t.Errorf("%v * %v: %v", v.input, v.r, err)
+			continue
+		}
+		if res.Money != v.expected || res.Rounded != v.rounded || res.RoundingError != v.roundingError {
+			t.Errorf("%v * %v: got %v, %v, %d expected %v, %v, %d", v.input, v.r, &res.Money, res.Rounded, res.RoundingError, &v.expected, v.rounded, v.roundingError)
+		}
+	}
+}
+
+func TestTrackedMoneyDiv(t *testing.T) {
+	cases := []struct {
+		input         *Money
+		divisor       float64
+		mode          RoundingMode
+		expected      Money
+		rounded       bool
+		roundingError int64
+	}{
+		{&Money{Units: 10, CurrencyCode: "USD"}, 4, HalfUp, Money{Units: 2, Nanos: 500000000, CurrencyCode: "USD"}, false, 0},
+		{&Money{Units: 1, CurrencyCode: "USD"}, 0.1, HalfUp, Money{Units: 10, CurrencyCode: "USD"}, false, 0},
+		{&Money{Units: 10, CurrencyCode: "USD"}, 3, HalfUp, Money{Units: 3, Nanos: 333333333, CurrencyCode: "USD"}, true, 333333333},
+		{&Money{Units: 10, CurrencyCode: "USD"}, 3, Ceiling, Money{Units: 3, Nanos: 333333334, CurrencyCode: "USD"}, true, -666666667},
+		{&Money{Nanos: 5, CurrencyCode: "USD"}, 2, HalfEven, Money{Nanos: 2, CurrencyCode: "USD"}, true, 500000000},
+	}
+
+	for _, v := range cases {
+		res, err := Track(v.input).Div(v.divisor, v.mode)
+		if err != nil {
+			t.Errorf("%v / %v: %v", v.input, v.divisor, err)
+			continue
+		}
+		if res.Money != v.expected || res.Rounded != v.rounded || res.RoundingError != v.roundingError {
+			t.Errorf("%v / %v: got %v, %v, %d expected %v, %v, %d", v.input, v.divisor, &res.Money, res.Rounded, res.RoundingError, &v.expected, v.rounded, v.roundingError)
+		}
+	}
+
+	tm := Track(&Money{Units: 1, CurrencyCode: "USD"})
+	if _, err := tm.Div(0, HalfUp); err != ErrDivisionByZero {
+		t.Errorf("got error %v expected %v", err, ErrDivisionByZero)
+	}
+	tm, err := tm.Div(3, Floor)
+	if err != nil || !tm.Rounded {
+		t.Fatalf("got %+v, %v expected a rounded quotient", tm, err)
+	}
+	if tm, err = tm.Mul(3); err != nil || !tm.Rounded || tm.RoundingError != 333333333 {
+		t.Errorf("got %+v, %v expected the rounding of Div to be remembered", tm, err)
+	}
+}
+
+func TestTrackedMoneyAccumulates(t *testing.T) {
+	tm := Track(&Money{Nanos: 1, CurrencyCode: "USD"})
+	if tm.Rounded || tm.RoundingError != 0 {
+		t.Fatalf("new value is already rounded: %+v", tm)
+	}
+
+	tm, err := tm.Mul(10)
+	if err != nil || tm.Rounded {
+		t.Fatalf("got %+v, %v expected an exact product", tm, err)
+	}
+	if tm, err = tm.Mul(0.15); err != nil || !tm.Rounded || tm.RoundingError != 500000000 {
+		t.Fatalf("got %+v, %v expected a rounded product", tm, err)
+	}
+	if tm, err = tm.Mul(2); err != nil || !tm.Rounded || tm.RoundingError != 500000000 {
+		t.Errorf("got %+v, %v expected rounding to be remembered", tm, err)
+	}
+	if p := tm.Plain(); *p != (Money{Nanos: 2, CurrencyCode: "USD"}) {
+		t.Errorf("Plain got %+v", *p)
+	}
+
+	if _, err := tm.Mul(-1); err != ErrInvalidMultiplierProvided {
+		t.Errorf("got error %v expected %v", err, ErrInvalidMultiplierProvided)
+	}
+}